@@ -0,0 +1,91 @@
+package errstack
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Capturer captures and symbolizes the program counters recorded by the errors. The default, RuntimeCapturer,
+// uses the runtime package. A different capturer can be installed with SetCapturer, e.g. a deterministic
+// fake for golden tests or NopCapturer to disable stack traces altogether.
+type Capturer interface {
+	// Capture fills pcs with the return program counters of the calling goroutine and returns the number of
+	// entries written. A skip of 0 identifies the caller of Capture.
+	Capture(skip int, pcs []uintptr) int
+	// Symbolize resolves program counters returned by Capture into frames
+	Symbolize(pcs []uintptr) []Frame
+}
+
+var (
+	_ Capturer = RuntimeCapturer{}
+	_ Capturer = NopCapturer{}
+)
+
+type capturerBox struct {
+	c Capturer
+}
+
+var activeCapturer atomic.Pointer[capturerBox]
+
+func init() {
+	activeCapturer.Store(&capturerBox{c: RuntimeCapturer{}})
+}
+
+// SetCapturer installs c as the capturer of every error created or thrown afterwards and returns the previous
+// one. A nil c restores RuntimeCapturer. The frame cache is dropped, since its frames came from the previous
+// capturer. Errors which recorded program counters before the switch are symbolized with c, so the capturer
+// is best installed before any error is created.
+func SetCapturer(c Capturer) Capturer {
+	if c == nil {
+		c = RuntimeCapturer{}
+	}
+
+	prev := activeCapturer.Swap(&capturerBox{c: c})
+	pcFrameCache.clear()
+
+	return prev.c
+}
+
+func capturer() Capturer {
+	return activeCapturer.Load().c
+}
+
+// RuntimeCapturer captures stacks with runtime.Callers and symbolizes them with runtime.CallersFrames
+type RuntimeCapturer struct{}
+
+func (RuntimeCapturer) Capture(skip int, pcs []uintptr) int {
+	return runtime.Callers(skip+2, pcs)
+}
+
+func (RuntimeCapturer) Symbolize(pcs []uintptr) []Frame {
+	frames := make([]Frame, 0, len(pcs))
+	callFrames := runtime.CallersFrames(pcs)
+
+	for {
+		f, ok := callFrames.Next()
+		frames = append(frames, Frame{
+			File:     f.File,
+			Function: f.Function,
+			Line:     f.Line,
+			PC:       f.PC,
+			Entry:    f.Entry,
+			Inlined:  f.Func == nil && f.Function != "",
+		})
+		if !ok {
+			break
+		}
+	}
+
+	return frames
+}
+
+// NopCapturer captures nothing, so that the errors carry no stack trace
+type NopCapturer struct{}
+
+func (NopCapturer) Capture(int, []uintptr) int {
+	return 0
+}
+
+func (NopCapturer) Symbolize([]uintptr) []Frame {
+	return nil
+}
@@ -0,0 +1,81 @@
+package errstack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCapturer struct {
+	depth int
+	names map[uintptr]string
+}
+
+func (self fakeCapturer) Capture(skip int, pcs []uintptr) int {
+	n := 0
+	for ; n < len(pcs) && n < self.depth; n++ {
+		pcs[n] = uintptr(100 + n)
+	}
+	return n
+}
+
+func (self fakeCapturer) Symbolize(pcs []uintptr) []Frame {
+	frames := make([]Frame, 0, len(pcs))
+	for _, pc := range pcs {
+		name, ok := self.names[pc]
+		if !ok {
+			name = fmt.Sprintf("fake.fn%d", pc)
+		}
+		frames = append(frames, Frame{Function: name, File: "fake.go", Line: int(pc), PC: pc})
+	}
+	return frames
+}
+
+func Test_Capturer(t *testing.T) {
+	t.Run("fake capturer", func(t *testing.T) {
+		defer SetCapturer(SetCapturer(fakeCapturer{depth: 2}))
+
+		err := NewString("fake", WithStack())
+		assert.Equal(t, []string{"fake.fn100", "fake.fn101"}, functionNames(err.StackTrace()))
+
+		thrown := NewString("thrown").Throw()
+		assert.Equal(t, []string{"fake.fn100"}, functionNames(thrown.StackTrace()))
+	})
+
+	t.Run("panic stack", func(t *testing.T) {
+		defer SetCapturer(SetCapturer(fakeCapturer{
+			depth: 5,
+			names: map[uintptr]string{101: "runtime.gopanic", 102: "runtime.panicmem"},
+		}))
+
+		err := Catch(func() error {
+			panic("boom")
+		})
+		assert.Equal(t, []string{"fake.fn103", "fake.fn104"}, functionNames(err.(Error).StackTrace()))
+	})
+
+	t.Run("nop capturer", func(t *testing.T) {
+		defer SetCapturer(SetCapturer(NopCapturer{}))
+
+		assert.Empty(t, NewString("nop", WithStack()).StackTrace().Frames)
+		assert.Empty(t, NewString("nop").Throw().StackTrace().Frames)
+	})
+
+	t.Run("restore runtime capturer", func(t *testing.T) {
+		prev := SetCapturer(nil)
+		assert.Equal(t, RuntimeCapturer{}, prev)
+		assert.Equal(t, RuntimeCapturer{}, SetCapturer(prev))
+
+		err := NewString("runtime", WithStack())
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Capturer.func4", err.StackTrace().Frames[0].Function)
+	})
+}
+
+func functionNames(s StackTrace) []string {
+	names := make([]string, 0, len(s.Frames))
+	for _, f := range s.Frames {
+		names = append(names, f.Function)
+	}
+	return names
+}
@@ -2,6 +2,7 @@ package errstack
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -21,6 +22,8 @@ func newChainedStacktraceError(err error, opts ...StackErrOption) *ChainedStackt
 
 	if stErr, ok := err.(Error); ok {
 		chainErr.currErr = stErr
+	} else if joined, ok := err.(interface{ Unwrap() []error }); ok && hasMembers(joined.Unwrap()) {
+		return newJoinedChain(joined.Unwrap(), opts...)
 	} else {
 		chainErr.currErr = New(err, opts...)
 	}
@@ -28,19 +31,73 @@ func newChainedStacktraceError(err error, opts ...StackErrOption) *ChainedStackt
 	return chainErr
 }
 
+// newJoinedChain links the members of an error created by errors.Join, or of a MultiError, one after the
+// other, so that every member keeps its own stack trace in the chain. The elements of chained members are
+// copied, leaving the members untouched.
+func newJoinedChain(errs []error, opts ...StackErrOption) *ChainedStacktraceError {
+	elems := make([]Error, 0, len(errs))
+
+	for _, err := range errs {
+		switch e := err.(type) {
+		case nil:
+		case ChainedError:
+			for elem := e; elem != nil; elem = elem.Next() {
+				if elem.Inner() != nil {
+					elems = append(elems, elem.Inner())
+				}
+			}
+		default:
+			for elem := (ChainedError)(newChainedStacktraceError(err, opts...)); elem != nil; elem = elem.Next() {
+				elems = append(elems, elem.Inner())
+			}
+		}
+	}
+
+	chainErr := (*ChainedStacktraceError)(nil)
+
+	for i := len(elems) - 1; i >= 0; i-- {
+		elem := &ChainedStacktraceError{currErr: elems[i]}
+		if chainErr != nil {
+			elem.nextErr = chainErr
+		}
+		chainErr = elem
+	}
+
+	return chainErr
+}
+
 func newChainedStacktraceErrorString(errStr string, opts ...StackErrOption) *ChainedStacktraceError {
 	return &ChainedStacktraceError{
 		currErr: NewString(errStr, opts...),
 	}
 }
 
+func hasMembers(errs []error) bool {
+	for _, err := range errs {
+		if err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 func Chain(err1, err2 error) ChainedError {
 	chErr1, ok1 := err1.(ChainedError)
 	if ok1 {
 		return chErr1.Chain(err2)
 	}
 
-	return newChainedStacktraceError(err1).Chain(err2)
+	chErr := newChainedStacktraceError(err1)
+
+	// err1 may have been a joined error, spanning several fresh elements
+	tail := chErr
+	for tail.nextErr != nil {
+		tail = tail.nextErr.(*ChainedStacktraceError)
+	}
+	tail.Chain(err2)
+
+	return chErr
 }
 
 type ChainedStacktraceError struct {
@@ -82,6 +139,44 @@ func (self *ChainedStacktraceError) Unwrap() []error {
 	return errList
 }
 
+// Is reports whether any element of the chain matches target. When target is a chain itself, its first
+// element is used for the comparison.
+func (self *ChainedStacktraceError) Is(target error) bool {
+	if self == nil {
+		return false
+	}
+
+	if chErr, ok := target.(ChainedError); ok {
+		if chErr.Inner() == nil {
+			return false
+		}
+		target = chErr.Inner()
+	}
+
+	for elem := (ChainedError)(self); elem != nil; elem = elem.Next() {
+		if inner := elem.Inner(); inner != nil && errors.Is(inner, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first element of the chain that matches target
+func (self *ChainedStacktraceError) As(target any) bool {
+	if self == nil {
+		return false
+	}
+
+	for elem := (ChainedError)(self); elem != nil; elem = elem.Next() {
+		if inner := elem.Inner(); inner != nil && errors.As(inner, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (self *ChainedStacktraceError) Next() ChainedError {
 	if self == nil {
 		return nil
@@ -92,14 +187,95 @@ func (self *ChainedStacktraceError) Next() ChainedError {
 
 //go:noinline
 func (self *ChainedStacktraceError) Throw() ChainedError {
+	return self.throwSkip(1)
+}
+
+//go:noinline
+func (self *ChainedStacktraceError) throwSkip(skip int) ChainedError {
 	if self == nil {
 		return nil
 	}
 
-	self.currErr.ThrowSkip(1)
+	return &ChainedStacktraceError{
+		nextErr: self.nextErr,
+		currErr: self.currErr.ThrowSkip(skip + 1),
+	}
+}
 
-	return self
+//go:noinline
+func (self *ChainedStacktraceError) ThrowWithFields(fields map[string]any) ChainedError {
+	if self == nil {
+		return nil
+	}
+
+	chErr := &ChainedStacktraceError{
+		nextErr: self.nextErr,
+	}
+
+	if stErr, ok := self.currErr.(*StacktraceError); ok {
+		chErr.currErr = stErr.throwWithFields(1, fields)
+	} else {
+		chErr.currErr = self.currErr.ThrowSkip(1)
+	}
+
+	return chErr
+}
+
+// ThrowMsg throws the first element of the chain with an annotation, see StacktraceError.ThrowMsg
+//
+//go:noinline
+func (self *ChainedStacktraceError) ThrowMsg(msg string) ChainedError {
+	return self.throwMsg(1, msg)
+}
+
+//go:noinline
+func (self *ChainedStacktraceError) throwMsg(skip int, msg string) ChainedError {
+	if self == nil {
+		return nil
+	}
+
+	chErr := &ChainedStacktraceError{
+		nextErr: self.nextErr,
+	}
+
+	if stErr, ok := self.currErr.(*StacktraceError); ok {
+		chErr.currErr = stErr.throwMsg(skip+1, msg)
+	} else {
+		chErr.currErr = adoptError(self.currErr).throwMsg(skip+1, msg)
+	}
+
+	return chErr
+}
+
+// Fields merges the fields of every element in the chain. In case of conflicting keys, the value
+// attached to the outermost element of the chain wins.
+func (self *ChainedStacktraceError) Fields() map[string]any {
+	if self == nil {
+		return nil
+	}
+
+	fieldList := ([]map[string]any)(nil)
+	for elem := (ChainedError)(self); elem != nil; elem = elem.Next() {
+		if f, ok := elem.Inner().(Fielder); ok {
+			fieldList = append(fieldList, f.Fields())
+		}
+	}
 
+	fields := (map[string]any)(nil)
+	for i := len(fieldList) - 1; i >= 0; i-- {
+		fields = mergeFields(fields, fieldList[i])
+	}
+
+	return fields
+}
+
+// Code returns the outermost code found in the chain
+func (self *ChainedStacktraceError) Code() Code {
+	if self == nil {
+		return CodeNone
+	}
+
+	return CodeOf(self)
 }
 
 func (self *ChainedStacktraceError) Error() string {
@@ -130,6 +306,45 @@ func (self *ChainedStacktraceError) MarshalJSON() ([]byte, error) {
 	return json.Marshal(errList)
 }
 
+// UnmarshalJSON rebuilds the chain from the output of MarshalJSON. Every element of the array becomes a
+// link of the chain, in the same order.
+func (self *ChainedStacktraceError) UnmarshalJSON(data []byte) error {
+	if self == nil {
+		return errNilUnmarshalTarget
+	}
+
+	if string(data) == "null" {
+		return nil
+	}
+
+	errList := ([]*StacktraceError)(nil)
+	if err := json.Unmarshal(data, &errList); err != nil {
+		return err
+	}
+
+	if len(errList) == 0 {
+		return errEmptyChain
+	}
+
+	chainErr := (*ChainedStacktraceError)(nil)
+
+	for i := len(errList) - 1; i >= 0; i-- {
+		if errList[i] == nil {
+			return errEmptyChainElement
+		}
+
+		elem := &ChainedStacktraceError{currErr: errList[i]}
+		if chainErr != nil {
+			elem.nextErr = chainErr
+		}
+		chainErr = elem
+	}
+
+	*self = *chainErr
+
+	return nil
+}
+
 func (self *ChainedStacktraceError) String() string {
 	if self == nil {
 		return NilErrorString
@@ -168,7 +383,8 @@ func (self *ChainedStacktraceError) String() string {
 //	%+v	Same as %-v, except it will print the stack trace on a separate line than the error. This
 //		overrides the stack frame separator and the error separator to '\n'. Rest of the options
 //		are kept intact. '+' can be followed by an arbitrary number which will represent the count
-//		of spaces used to indent the stack trace
+//		of spaces used to indent the stack trace. Fields attached to each error are printed after
+//		the error string
 //
 //	%#v	Same as %+(n)v, except it will print stack indices as well
 //
@@ -220,6 +436,10 @@ func (self *ChainedStacktraceError) Format(s fmt.State, verb rune) {
 		stFmt = stFmt.Copy()
 		ffFmt = ffFmt.Copy()
 
+		if flags&0x0c > 0 {
+			eOpts.ShowFields = true
+		}
+
 		if flags&0x0d > 0 {
 			eOpts.ErrorSeparator = "\n"
 			eOpts.StackTraceSeparator = "\n"
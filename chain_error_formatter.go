@@ -19,21 +19,32 @@ func (self *chainErrorFormatter) format(w io.Writer, err error) {
 	}
 
 	for err != nil {
-		prefix, errStr := self.opts.ErrorPrefix, ""
+		prefix, elem := self.opts.ErrorPrefix, err
 
 		if chErr, ok := err.(ChainedError); ok {
-			errStr = chErr.Inner().Error()
-		} else {
-			errStr = err.Error()
+			elem = chErr.Inner()
 		}
 
 		switch o := w.(type) {
 		case io.StringWriter:
 			o.WriteString(prefix)
-			o.WriteString(errStr)
 		default:
 			w.Write(string2Slice(prefix))
-			w.Write(string2Slice(errStr))
+		}
+
+		if c, ok := elem.(Coder); ok && self.opts.ShowCode {
+			formatCode(w, c.Code(), self.opts)
+		}
+
+		switch o := w.(type) {
+		case io.StringWriter:
+			o.WriteString(elem.Error())
+		default:
+			w.Write(string2Slice(elem.Error()))
+		}
+
+		if f, ok := elem.(Fielder); ok && self.opts.ShowFields {
+			formatFields(w, f.Fields(), self.opts)
 		}
 
 		switch {
@@ -47,6 +58,10 @@ func (self *chainErrorFormatter) format(w io.Writer, err error) {
 				stackTrace = stErr.StackTrace()
 			}
 
+			if len(stackTrace.Frames) <= 0 {
+				stackTrace, _ = ExtractStackTrace(elem)
+			}
+
 			if len(stackTrace.Frames) > 0 {
 				switch o := w.(type) {
 				case io.StringWriter:
@@ -0,0 +1,136 @@
+package errstack_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nnishant776/errstack"
+	"github.com/nnishant776/errstack/errstacktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ChainedErrorWithDefaults(t *testing.T) {
+	t.Run("automatic stacktrace collection", func(t *testing.T) {
+		chainabc3 := func() errstack.ChainedError {
+			return errstack.NewChain(errstack.NewString("Error 2", errstack.WithStack()))
+		}
+		chainabc2 := func() errstack.ChainedError {
+			return chainabc3()
+		}
+		chainabc1 := func() errstack.ChainedError {
+			return errstack.Chain(errstack.NewString("Error 1", errstack.WithStack()), chainabc2())
+		}
+
+		err := chainabc1()
+
+		t.Run("printing formats", func(t *testing.T) {
+			cases := []struct {
+				format string
+				expect string
+			}{
+				{
+					format: "s",
+					expect: "Error 1, Error 2",
+				},
+				{
+					format: "+s",
+					expect: "Error 1: Error 2",
+				},
+				{
+					format: "v",
+					expect: "Error 1=>github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1, Error 2=>github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.1;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.2;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1",
+				},
+				{
+					format: " v",
+					expect: "Error 1\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1\nError 2\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.1\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.2\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1",
+				},
+				{
+					format: "-v",
+					expect: "Error 1=>github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3@chain_format_test.go:0;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1@chain_format_test.go:0, Error 2=>github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.1@chain_format_test.go:0;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.2@chain_format_test.go:0;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3@chain_format_test.go:0;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1@chain_format_test.go:0",
+				},
+				{
+					format: "+v",
+					expect: "Error 1\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3@chain_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1@chain_format_test.go:0\nError 2\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.1@chain_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.2@chain_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3@chain_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1@chain_format_test.go:0",
+				},
+				{
+					format: "#v",
+					expect: "Error 1\n#3: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3@chain_format_test.go:0\n#2: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1@chain_format_test.go:0\nError 2\n#5: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.1@chain_format_test.go:0\n#4: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.2@chain_format_test.go:0\n#3: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1.3@chain_format_test.go:0\n#2: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func1@chain_format_test.go:0",
+				},
+			}
+
+			for _, c := range cases {
+				t.Run(c.format, func(t *testing.T) {
+					assert.Equal(t, c.expect, errstacktest.SprintfIndexed("%"+c.format, err), "Doesn't match the expected output")
+				})
+			}
+
+			assertJSONFormats(t, err, chainElements(err))
+		})
+	})
+
+	t.Run("manual stacktrace collection", func(t *testing.T) {
+		chainabc3 := func() errstack.ChainedError {
+			return errstack.NewChain(errors.New("Error 2")).Throw()
+		}
+		chainabc2 := func() errstack.ChainedError {
+			return chainabc3().Throw()
+		}
+		chainabc1 := func() errstack.ChainedError {
+			return errstack.Chain(errstack.NewString("Error 1"), chainabc2()).Throw()
+		}
+
+		err := chainabc1().Throw()
+
+		t.Run("printing formats", func(t *testing.T) {
+			cases := []struct {
+				format string
+				expect string
+			}{
+				{
+					format: "s",
+					expect: "Error 1, Error 2",
+				},
+				{
+					format: "+s",
+					expect: "Error 1: Error 2",
+				},
+				{
+					format: "v",
+					expect: "Error 1=>github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.3;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2, Error 2=>github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.1;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.2",
+				},
+				{
+					format: " v",
+					expect: "Error 1\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.3\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2\nError 2\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.1\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.2",
+				},
+				{
+					format: "-v",
+					expect: "Error 1=>github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.3@chain_format_test.go:0;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2@chain_format_test.go:0, Error 2=>github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.1@chain_format_test.go:0;github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.2@chain_format_test.go:0",
+				},
+				{
+					format: "+v",
+					expect: "Error 1\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.3@chain_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2@chain_format_test.go:0\nError 2\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.1@chain_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.2@chain_format_test.go:0",
+				},
+				{
+					format: "#v",
+					expect: "Error 1\n#1: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.3@chain_format_test.go:0\n#0: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2@chain_format_test.go:0\nError 2\n#1: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.1@chain_format_test.go:0\n#0: github.com/nnishant776/errstack_test.Test_ChainedErrorWithDefaults.func2.2@chain_format_test.go:0",
+				},
+			}
+
+			for _, c := range cases {
+				t.Run(c.format, func(t *testing.T) {
+					assert.Equal(t, c.expect, errstacktest.SprintfIndexed("%"+c.format, err), "Doesn't match the expected output")
+				})
+			}
+
+			assertJSONFormats(t, err, chainElements(err))
+		})
+	})
+}
+
+func chainElements(err errstack.ChainedError) []errstack.Error {
+	errList := []errstack.Error{}
+	for chErr := err; chErr != nil; chErr = chErr.Next() {
+		errList = append(errList, chErr.Inner())
+	}
+	return errList
+}
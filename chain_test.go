@@ -5,8 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"runtime"
 	"strings"
 	"testing"
 
@@ -14,212 +12,69 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_ChainedErrorWithDefaults(t *testing.T) {
-	goRoot := runtime.GOROOT()
-	currDir, err := os.Getwd()
-	if err != nil {
-		t.Errorf("Failed to get current directory: error: %s", err)
+func Test_ChainedStacktraceErrorJSONRoundTrip(t *testing.T) {
+	chainabc2 := func() ChainedError {
+		return NewChain(NewString("Error 2", WithStack()))
+	}
+	chainabc1 := func() ChainedError {
+		return Chain(NewString("Error 1", WithStack()), chainabc2())
 	}
 
-	t.Run("automatic stacktrace collection", func(t *testing.T) {
-		chainabc3 := func() ChainedError {
-			return NewChain(NewString("Error 2", WithStack()))
-		}
-		chainabc2 := func() ChainedError {
-			return chainabc3()
-		}
-		chainabc1 := func() ChainedError {
-			return Chain(NewString("Error 1", WithStack()), chainabc2())
-		}
+	err := chainabc1()
+	encoded := fmt.Sprintf("%j", err)
 
-		err := chainabc1()
+	decoded := &ChainedStacktraceError{}
+	if !assert.NoError(t, json.Unmarshal([]byte(encoded), decoded)) {
+		return
+	}
 
-		t.Run("printing formats", func(t *testing.T) {
-			type testCase struct {
-				format string
-				output strings.Builder
-				expect string
-			}
+	assert.Equal(t, err.Error(), decoded.Error())
+	assert.Equal(t, encoded, fmt.Sprintf("%j", decoded))
+	assert.Equal(t, fmt.Sprintf("%#v", err), fmt.Sprintf("%#v", decoded))
 
-			cases := []testCase{
-				{
-					format: "s",
-					expect: "Error 1, Error 2",
-				},
-				{
-					format: "+s",
-					expect: "Error 1: Error 2",
-				},
-				{
-					format: "v",
-					expect: "Error 1=>github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1;testing.tRunner;runtime.goexit, Error 2=>github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.1;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.2;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1;testing.tRunner;runtime.goexit",
-				},
-				{
-					format: " v",
-					expect: "Error 1\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1\ntesting.tRunner\nruntime.goexit\nError 2\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.1\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.2\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1\ntesting.tRunner\nruntime.goexit",
-				},
-				{
-					format: "-v",
-					expect: fmt.Sprintf("Error 1=>github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3@%s/chain_test.go:32;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1@%s/chain_test.go:35;testing.tRunner@%s/src/testing/testing.go:1690;runtime.goexit@%s/src/runtime/asm_amd64.s:1700, Error 2=>github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.1@%s/chain_test.go:26;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.2@%s/chain_test.go:29;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3@%s/chain_test.go:32;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1@%s/chain_test.go:35;testing.tRunner@%s/src/testing/testing.go:1690;runtime.goexit@%s/src/runtime/asm_amd64.s:1700", currDir, currDir, goRoot, goRoot, currDir, currDir, currDir, currDir, goRoot, goRoot),
-				},
-				{
-					format: "+v",
-					expect: fmt.Sprintf("Error 1\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3@%s/chain_test.go:32\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1@%s/chain_test.go:35\ntesting.tRunner@%s/src/testing/testing.go:1690\nruntime.goexit@%s/src/runtime/asm_amd64.s:1700\nError 2\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.1@%s/chain_test.go:26\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.2@%s/chain_test.go:29\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3@%s/chain_test.go:32\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1@%s/chain_test.go:35\ntesting.tRunner@%s/src/testing/testing.go:1690\nruntime.goexit@%s/src/runtime/asm_amd64.s:1700", currDir, currDir, goRoot, goRoot, currDir, currDir, currDir, currDir, goRoot, goRoot),
-				},
-				{
-					format: "#v",
-					expect: fmt.Sprintf("Error 1\n#3: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3@%s/chain_test.go:32\n#2: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1@%s/chain_test.go:35\n#1: testing.tRunner@%s/src/testing/testing.go:1690\n#0: runtime.goexit@%s/src/runtime/asm_amd64.s:1700\nError 2\n#5: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.1@%s/chain_test.go:26\n#4: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.2@%s/chain_test.go:29\n#3: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1.3@%s/chain_test.go:32\n#2: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func1@%s/chain_test.go:35\n#1: testing.tRunner@%s/src/testing/testing.go:1690\n#0: runtime.goexit@%s/src/runtime/asm_amd64.s:1700", currDir, currDir, goRoot, goRoot, currDir, currDir, currDir, currDir, goRoot, goRoot),
-				},
-				{
-					format: "j",
-					expect: func() string {
-						errList := []Error{}
-						for chErr := err; chErr != nil; chErr = chErr.Next() {
-							errList = append(errList, chErr.Inner())
-						}
-						b, _ := json.Marshal(errList)
-						return string(b) + "\n"
-					}(),
-				},
-				{
-					format: "+j",
-					expect: func() string {
-						errList := []Error{}
-						for chErr := err; chErr != nil; chErr = chErr.Next() {
-							errList = append(errList, chErr.Inner())
-						}
-						b, _ := json.MarshalIndent(errList, "", "  ")
-						return string(b) + "\n"
-					}(),
-				},
-				{
-					format: "+4j",
-					expect: func() string {
-						errList := []Error{}
-						for chErr := err; chErr != nil; chErr = chErr.Next() {
-							errList = append(errList, chErr.Inner())
-						}
-						b, _ := json.MarshalIndent(errList, "", "    ")
-						return string(b) + "\n"
-					}(),
-				},
-			}
+	for chErr, decErr := err, ChainedError(decoded); chErr != nil; chErr, decErr = chErr.Next(), decErr.Next() {
+		if !assert.NotNil(t, decErr) {
+			return
+		}
+		assert.Equal(t, withoutAddresses(chErr.Inner().StackTrace()), decErr.Inner().StackTrace())
+	}
 
-			for _, c := range cases {
-				t.Run(c.format, func(t *testing.T) {
-					fmt.Fprintf(&c.output, "%"+c.format, err)
-					if c.output.String() != c.expect {
-						assert.Equal(
-							t,
-							c.expect,
-							c.output.String(),
-							"Doesn't match the expected output",
-						)
-					}
-				})
-			}
-		})
-	})
+	assert.True(t, errors.Is(decoded, decoded.Next().Inner()))
+	assert.Error(t, json.Unmarshal([]byte(`[]`), &ChainedStacktraceError{}))
+	assert.Error(t, json.Unmarshal([]byte(`[null]`), &ChainedStacktraceError{}))
+}
 
-	t.Run("manual stacktrace collection", func(t *testing.T) {
-		chainabc3 := func() ChainedError {
-			return NewChain(errors.New("Error 2")).Throw()
-		}
-		chainabc2 := func() ChainedError {
-			return chainabc3().Throw()
-		}
-		chainabc1 := func() ChainedError {
-			return Chain(NewString("Error 1"), chainabc2()).Throw()
-		}
+func Test_ChainedStacktraceErrorFields(t *testing.T) {
+	inner := NewChainString("Error 2", WithFields(map[string]any{"user_id": 42, "table": "users"}))
+	err := Chain(NewString("Error 1", WithFields(map[string]any{"user_id": 43})), inner).(*ChainedStacktraceError).
+		ThrowWithFields(map[string]any{"request_id": "abc"})
 
-		err := chainabc1().Throw()
+	assert.Equal(t, map[string]any{"request_id": "abc", "user_id": 43, "table": "users"}, err.(Fielder).Fields())
+	assert.Equal(t, "Error 1, Error 2", fmt.Sprintf("%s", err))
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), "Error 1 {request_id=abc, user_id=43}\n"))
+	assert.Contains(t, fmt.Sprintf("%+v", err), "\nError 2 {table=users, user_id=42}")
+}
 
-		t.Run("printing formats", func(t *testing.T) {
-			type testCase struct {
-				format string
-				output strings.Builder
-				expect string
-			}
+func Test_ChainedStacktraceErrorIs(t *testing.T) {
+	errNotFound := NewSentinel("not found")
+	errPermission := errors.New("permission denied")
 
-			cases := []testCase{
-				{
-					format: "s",
-					expect: "Error 1, Error 2",
-				},
-				{
-					format: "+s",
-					expect: "Error 1: Error 2",
-				},
-				{
-					format: "v",
-					expect: "Error 1=>github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.3;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2, Error 2=>github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.1;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.2",
-				},
-				{
-					format: " v",
-					expect: "Error 1\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.3\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2\nError 2\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.1\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.2",
-				},
-				{
-					format: "-v",
-					expect: fmt.Sprintf("Error 1=>github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.3@%s/chain_test.go:132;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2@%s/chain_test.go:135, Error 2=>github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.1@%s/chain_test.go:126;github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.2@%s/chain_test.go:129", currDir, currDir, currDir, currDir),
-				},
-				{
-					format: "+v",
-					expect: fmt.Sprintf("Error 1\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.3@%s/chain_test.go:132\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2@%s/chain_test.go:135\nError 2\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.1@%s/chain_test.go:126\ngithub.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.2@%s/chain_test.go:129", currDir, currDir, currDir, currDir),
-				},
-				{
-					format: "#v",
-					expect: fmt.Sprintf("Error 1\n#1: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.3@%s/chain_test.go:132\n#0: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2@%s/chain_test.go:135\nError 2\n#1: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.1@%s/chain_test.go:126\n#0: github.com/nnishant776/errstack.Test_ChainedErrorWithDefaults.func2.2@%s/chain_test.go:129", currDir, currDir, currDir, currDir),
-				},
-				{
-					format: "j",
-					expect: func() string {
-						errList := []Error{}
-						for chErr := err; chErr != nil; chErr = chErr.Next() {
-							errList = append(errList, chErr.Inner())
-						}
-						b, _ := json.Marshal(errList)
-						return string(b) + "\n"
-					}(),
-				},
-				{
-					format: "+j",
-					expect: func() string {
-						errList := []Error{}
-						for chErr := err; chErr != nil; chErr = chErr.Next() {
-							errList = append(errList, chErr.Inner())
-						}
-						b, _ := json.MarshalIndent(errList, "", "  ")
-						return string(b) + "\n"
-					}(),
-				},
-				{
-					format: "+4j",
-					expect: func() string {
-						errList := []Error{}
-						for chErr := err; chErr != nil; chErr = chErr.Next() {
-							errList = append(errList, chErr.Inner())
-						}
-						b, _ := json.MarshalIndent(errList, "", "    ")
-						return string(b) + "\n"
-					}(),
-				},
-			}
+	err := Chain(NewString("loading config"), NewChain(errNotFound.Throw()).Chain(errPermission))
 
-			for _, c := range cases {
-				t.Run(c.format, func(t *testing.T) {
-					fmt.Fprintf(&c.output, "%"+c.format, err)
-					if c.output.String() != c.expect {
-						assert.Equal(
-							t,
-							c.expect,
-							c.output.String(),
-							"Doesn't match the expected output",
-						)
-					}
-				})
-			}
-		})
-	})
+	assert.True(t, errors.Is(err, errNotFound))
+	assert.True(t, errors.Is(err, errPermission))
+	assert.True(t, errors.Is(err, NewChain(errNotFound.Throw())))
+	assert.False(t, errors.Is(err, NewSentinel("not found")))
+
+	target := (*StacktraceError)(nil)
+	if assert.True(t, errors.As(err, &target)) {
+		assert.Equal(t, "loading config", target.Error())
+	}
+
+	chErr := NewChain(errNotFound).Throw()
+	assert.NotSame(t, errNotFound, chErr.Inner())
+	assert.True(t, errors.Is(chErr, errNotFound))
+	assert.Empty(t, errNotFound.StackTrace().Frames)
 }
 
 func Benchmark_ChainedStackTraceError(b *testing.B) {
@@ -0,0 +1,128 @@
+package errstack
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Code classifies an error, e.g. for mapping it to an HTTP or a gRPC status. A Code is also an error, so
+// that errors.Is(err, CodeNotFound) reports whether err carries the code.
+type Code uint32
+
+const (
+	CodeNone Code = iota
+	CodeCanceled
+	CodeUnknown
+	CodeInvalidArgument
+	CodeDeadlineExceeded
+	CodeNotFound
+	CodeAlreadyExists
+	CodePermissionDenied
+	CodeResourceExhausted
+	CodeFailedPrecondition
+	CodeAborted
+	CodeOutOfRange
+	CodeUnimplemented
+	CodeInternal
+	CodeUnavailable
+	CodeDataLoss
+	CodeUnauthenticated
+)
+
+var codeNames = [...]string{
+	CodeNone:               "None",
+	CodeCanceled:           "Canceled",
+	CodeUnknown:            "Unknown",
+	CodeInvalidArgument:    "InvalidArgument",
+	CodeDeadlineExceeded:   "DeadlineExceeded",
+	CodeNotFound:           "NotFound",
+	CodeAlreadyExists:      "AlreadyExists",
+	CodePermissionDenied:   "PermissionDenied",
+	CodeResourceExhausted:  "ResourceExhausted",
+	CodeFailedPrecondition: "FailedPrecondition",
+	CodeAborted:            "Aborted",
+	CodeOutOfRange:         "OutOfRange",
+	CodeUnimplemented:      "Unimplemented",
+	CodeInternal:           "Internal",
+	CodeUnavailable:        "Unavailable",
+	CodeDataLoss:           "DataLoss",
+	CodeUnauthenticated:    "Unauthenticated",
+}
+
+type Coder interface {
+	Code() Code
+}
+
+func (self Code) String() string {
+	if int(self) < len(codeNames) {
+		return codeNames[self]
+	}
+
+	return fmt.Sprintf("Code(%d)", uint32(self))
+}
+
+func (self Code) Error() string {
+	return self.String()
+}
+
+func (self Code) MarshalText() ([]byte, error) {
+	return []byte(self.String()), nil
+}
+
+// UnmarshalText accepts the names of the codes, as well as the "Code(N)" form written for the codes without
+// a name
+func (self *Code) UnmarshalText(data []byte) error {
+	for i, name := range codeNames {
+		if name == string(data) {
+			*self = Code(i)
+			return nil
+		}
+	}
+
+	if num, ok := strings.CutPrefix(string(data), "Code("); ok {
+		if num, ok = strings.CutSuffix(num, ")"); ok {
+			if n, err := strconv.ParseUint(num, 10, 32); err == nil {
+				*self = Code(n)
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("errstack: unknown code %q", data)
+}
+
+// CodeOf returns the outermost code found in err. It walks the elements of a ChainedError as well as the
+// errors returned by Unwrap, and returns CodeNone if none of them carries a code.
+func CodeOf(err error) Code {
+	for err != nil {
+		switch e := err.(type) {
+		case ChainedError:
+			for elem := e; elem != nil; elem = elem.Next() {
+				if code := CodeOf(elem.Inner()); code != CodeNone {
+					return code
+				}
+			}
+			return CodeNone
+
+		case Coder:
+			if code := e.Code(); code != CodeNone {
+				return code
+			}
+		}
+
+		if e, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, inner := range e.Unwrap() {
+				if code := CodeOf(inner); code != CodeNone {
+					return code
+				}
+			}
+			return CodeNone
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return CodeNone
+}
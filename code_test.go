@@ -0,0 +1,74 @@
+package errstack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Code(t *testing.T) {
+	t.Run("code lookup", func(t *testing.T) {
+		errNotFound := NewSentinel("not found", WithCode(CodeNotFound))
+
+		assert.Equal(t, CodeNone, CodeOf(nil))
+		assert.Equal(t, CodeNone, CodeOf(errors.New("plain")))
+		assert.Equal(t, CodeNotFound, CodeOf(errNotFound.Throw()))
+		assert.Equal(t, CodeNotFound, CodeOf(fmt.Errorf("wrapped: %w", errNotFound.Throw())))
+		assert.Equal(t, CodeNotFound, CodeOf(errors.Join(errors.New("plain"), errNotFound)))
+		assert.Equal(t, CodeNotFound, CodeOf(New(fmt.Errorf("wrapped: %w", errNotFound))))
+
+		chErr := Chain(NewString("loading user"), NewChain(errNotFound.Throw()))
+		assert.Equal(t, CodeNotFound, CodeOf(chErr))
+
+		chErr = Chain(NewString("loading user", WithCode(CodeUnavailable)), chErr)
+		assert.Equal(t, CodeUnavailable, CodeOf(chErr))
+		assert.Equal(t, CodeUnavailable, chErr.(Coder).Code())
+
+		assert.True(t, errors.Is(chErr, CodeNotFound))
+		assert.True(t, errors.Is(errNotFound.Throw(), CodeNotFound))
+		assert.False(t, errors.Is(NewString("not found"), CodeNotFound))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		err := NewString("invalid id", WithCode(CodeInvalidArgument))
+		encoded := fmt.Sprintf("%j", err)
+		assert.Contains(t, encoded, `"code":"InvalidArgument"`)
+
+		decoded := &StacktraceError{}
+		if assert.NoError(t, json.Unmarshal([]byte(encoded), decoded)) {
+			assert.Equal(t, CodeInvalidArgument, decoded.Code())
+		}
+
+		assert.Error(t, json.Unmarshal([]byte(`{"error":"x","code":"Bogus"}`), decoded))
+		assert.Error(t, json.Unmarshal([]byte(`{"error":"x","code":"Code(-1)"}`), decoded))
+		assert.Equal(t, "Code(99)", Code(99).String())
+
+		custom := NewString("quota exceeded", WithCode(Code(99)))
+		encoded = fmt.Sprintf("%j", custom)
+		assert.Contains(t, encoded, `"code":"Code(99)"`)
+		if assert.NoError(t, json.Unmarshal([]byte(encoded), decoded)) {
+			assert.Equal(t, Code(99), decoded.Code())
+		}
+	})
+
+	t.Run("formatter prefix", func(t *testing.T) {
+		err := Chain(NewString("loading user"), NewString("not found", WithCode(CodeNotFound)))
+
+		assert.Equal(t, "loading user, not found", fmt.Sprintf("%s", err))
+
+		erFmt := DefaultChainErrorFormatter.Copy()
+		eOpts := erFmt.Options()
+		eOpts.ShowCode = true
+		erFmt.SetOptions(eOpts)
+		assert.Equal(t, "loading user, [NotFound] not found", erFmt.Format(err))
+
+		erFmt = DefaultStackErrorFormatter.Copy()
+		eOpts = erFmt.Options()
+		eOpts.ShowCode, eOpts.ErrorPrefix = true, "Error: "
+		erFmt.SetOptions(eOpts)
+		assert.Equal(t, "Error: [NotFound] not found", erFmt.Format(err.Next().Inner()))
+	})
+}
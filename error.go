@@ -1,4 +1,15 @@
 package errstack
 
+import (
+	"errors"
+)
+
 var NilErrorString = "<nil>"
 var ErrorChainSeparator = ": "
+
+var (
+	errNilUnmarshalTarget = errors.New("errstack: UnmarshalJSON on nil pointer")
+	errMissingErrorField  = errors.New("errstack: missing \"error\" field in json payload")
+	errEmptyChain         = errors.New("errstack: empty error chain in json payload")
+	errEmptyChainElement  = errors.New("errstack: null element in json error chain")
+)
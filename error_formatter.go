@@ -1,7 +1,9 @@
 package errstack
 
 import (
+	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -20,6 +22,17 @@ type ErrorFormatterOptions struct {
 	ErrorPrefix         string
 	ErrorSeparator      string
 	StackTraceSeparator string
+	FieldsPrefix        string
+	FieldsSuffix        string
+	FieldSeparator      string
+	CodePrefix          string
+	CodeSuffix          string
+	ShowFields          bool
+	ShowCode            bool
+	// MemberSeparator separates the members of a MultiError
+	MemberSeparator string
+	// MemberIndent is written at the start of every line of a member of a MultiError
+	MemberIndent string
 }
 
 var _ ErrorFormatter = (*errorFormatter)(nil)
@@ -40,12 +53,25 @@ func (self *errorFormatter) format(w io.Writer, err error) {
 	switch o := w.(type) {
 	case io.StringWriter:
 		o.WriteString(prefix)
-		o.WriteString(errStr)
 	default:
 		w.Write(string2Slice(prefix))
+	}
+
+	if c, ok := err.(Coder); ok && self.opts.ShowCode {
+		formatCode(w, c.Code(), self.opts)
+	}
+
+	switch o := w.(type) {
+	case io.StringWriter:
+		o.WriteString(errStr)
+	default:
 		w.Write(string2Slice(errStr))
 	}
 
+	if f, ok := err.(Fielder); ok && self.opts.ShowFields {
+		formatFields(w, f.Fields(), self.opts)
+	}
+
 	switch {
 	case self.stFmt == nil, self.opts.StackTraceSeparator == "":
 	default:
@@ -106,3 +132,59 @@ func (self *errorFormatter) SetStackTraceFormatter(stFmt StackTraceFormatter) Er
 	self.stFmt = stFmt
 	return self
 }
+
+func formatFields(w io.Writer, fields map[string]any, opts ErrorFormatterOptions) {
+	if len(fields) <= 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	switch o := w.(type) {
+	case io.StringWriter:
+		o.WriteString(opts.FieldsPrefix)
+	default:
+		w.Write(string2Slice(opts.FieldsPrefix))
+	}
+
+	for i, k := range keys {
+		if i > 0 {
+			switch o := w.(type) {
+			case io.StringWriter:
+				o.WriteString(opts.FieldSeparator)
+			default:
+				w.Write(string2Slice(opts.FieldSeparator))
+			}
+		}
+
+		fmt.Fprintf(w, "%s=%v", k, fields[k])
+	}
+
+	switch o := w.(type) {
+	case io.StringWriter:
+		o.WriteString(opts.FieldsSuffix)
+	default:
+		w.Write(string2Slice(opts.FieldsSuffix))
+	}
+}
+
+func formatCode(w io.Writer, code Code, opts ErrorFormatterOptions) {
+	if code == CodeNone {
+		return
+	}
+
+	switch o := w.(type) {
+	case io.StringWriter:
+		o.WriteString(opts.CodePrefix)
+		o.WriteString(code.String())
+		o.WriteString(opts.CodeSuffix)
+	default:
+		w.Write(string2Slice(opts.CodePrefix))
+		w.Write(string2Slice(code.String()))
+		w.Write(string2Slice(opts.CodeSuffix))
+	}
+}
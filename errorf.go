@@ -0,0 +1,114 @@
+package errstack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Errorf formats the error like fmt.Errorf and captures the stack of the caller. The operands of the %w
+// verbs stay reachable as the next element of the returned chain, keeping their stack traces: a single
+// wrapped error follows the first element as is, while several become the siblings of a MultiError. Since
+// the chain prints the wrapped errors, they are left out of the first element, e.g.
+// Errorf("loading %s: %w", p, err) holds "loading <p>" and is followed by err. The %w verbs which don't
+// refer to an error are printed as fmt does, e.g. %!w(<nil>). When nothing but the %w verbs is left, e.g.
+// Errorf("%w", err), the wrapped error is rethrown from the caller instead.
+//
+//go:noinline
+func Errorf(format string, args ...any) *ChainedStacktraceError {
+	msgFormat, msgArgs, wrapped, ok := splitWrapVerbs(format, args)
+	if !ok {
+		return &ChainedStacktraceError{
+			currErr: newStacktraceError(fmt.Errorf(format, args...), WithStack()),
+		}
+	}
+
+	if len(wrapped) <= 0 {
+		return &ChainedStacktraceError{
+			currErr: newStacktraceErrorString(fmt.Errorf(format, args...).Error(), WithStack()),
+		}
+	}
+
+	msg := strings.Trim(fmt.Sprintf(msgFormat, msgArgs...), " :;,")
+
+	next := newJoinedChain(wrapped)
+	if len(wrapped) > 1 {
+		next = &ChainedStacktraceError{currErr: New(NewMultiError(wrapped...))}
+	}
+
+	if msg == "" {
+		return &ChainedStacktraceError{
+			nextErr: next.nextErr,
+			currErr: next.currErr.ThrowSkip(1),
+		}
+	}
+
+	return &ChainedStacktraceError{
+		nextErr: next,
+		currErr: newStacktraceErrorString(msg, WithStack()),
+	}
+}
+
+// splitWrapVerbs replaces the %w verbs of format which refer to a non nil error with %s verbs printing
+// nothing, dropping a space doubled by the removal, and returns these errors. The other %w verbs are kept,
+// so that fmt reports them. It reports false for the formats it can't handle, i.e. explicit argument indexes.
+func splitWrapVerbs(format string, args []any) (string, []any, []error, bool) {
+	if !strings.Contains(format, "w") {
+		return format, args, nil, true
+	}
+
+	sb := strings.Builder{}
+	sb.Grow(len(format))
+
+	msgArgs := append([]any(nil), args...)
+	wrapped, argNum := ([]error)(nil), 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			continue
+		}
+
+		start := i
+		for i++; i < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i]) >= 0; i++ {
+			switch format[i] {
+			case '[', ']':
+				return "", nil, nil, false
+			case '*':
+				argNum++
+			}
+		}
+
+		if i >= len(format) {
+			sb.WriteString(format[start:])
+			break
+		}
+
+		sb.WriteString(format[start:i])
+
+		switch format[i] {
+		case '%':
+			sb.WriteByte('%')
+			continue
+		case 'w':
+			if argNum < len(args) {
+				if err, ok := args[argNum].(error); ok && err != nil {
+					wrapped = append(wrapped, err)
+					msgArgs[argNum] = ""
+					sb.WriteByte('s')
+
+					if strings.HasSuffix(sb.String(), " %s") && strings.HasPrefix(format[i+1:], " ") {
+						i++
+					}
+					break
+				}
+			}
+			sb.WriteByte('w')
+		default:
+			sb.WriteByte(format[i])
+		}
+
+		argNum++
+	}
+
+	return sb.String(), msgArgs, wrapped, true
+}
@@ -0,0 +1,101 @@
+package errstack
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Errorf(t *testing.T) {
+	t.Run("single wrapped error", func(t *testing.T) {
+		inner := NewString("file not found", WithStack())
+
+		err := Errorf("loading %s: %w", "config.yaml", inner)
+		assert.Equal(t, "loading config.yaml, file not found", err.Error())
+		assert.ErrorIs(t, err, inner)
+
+		if !assert.NotNil(t, err.Next()) {
+			t.FailNow()
+		}
+
+		assert.Equal(t, inner, err.Next().Inner())
+		assert.Nil(t, err.Next().Next())
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Errorf.func1", err.Inner().StackTrace().Frames[0].Function)
+		assert.Equal(t, inner.StackTrace(), err.Next().Inner().StackTrace())
+	})
+
+	t.Run("multiple wrapped errors", func(t *testing.T) {
+		err := Errorf("closing %d files: %w, %w", 2, io.ErrClosedPipe, os.ErrClosed)
+		assert.Equal(t, "closing 2 files", err.Inner().Error())
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		assert.ErrorIs(t, err, os.ErrClosed)
+
+		if assert.NotNil(t, err.Next()) {
+			assert.Nil(t, err.Next().Next())
+
+			multiErr := (*MultiError)(nil)
+			if assert.ErrorAs(t, err.Next().Inner(), &multiErr) {
+				assert.Equal(t, []error{io.ErrClosedPipe, os.ErrClosed}, multiErr.Errors())
+			}
+		}
+	})
+
+	t.Run("non error operand", func(t *testing.T) {
+		// A variable format keeps vet from rejecting the operand
+		format := "closing %w: %w"
+
+		err := Errorf(format, "file", io.ErrClosedPipe)
+		assert.Equal(t, "closing %!w(string=file), io: read/write on closed pipe", err.Error())
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+
+	t.Run("stack traced operands", func(t *testing.T) {
+		err := Errorf("a: %w, b: %w", NewString("e1", WithStack()), errors.New("e2"))
+		assert.Equal(t, "a: , b", err.Inner().Error())
+		assert.Equal(t, "a: , b, e1; e2", err.Error())
+
+		err = Errorf("open %w failed", io.EOF)
+		assert.Equal(t, "open failed, EOF", err.Error())
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("wrapped chain", func(t *testing.T) {
+		inner := NewChainString("outer").Chain(NewString("inner"))
+
+		err := Errorf("request failed: %w", inner)
+		assert.Equal(t, "request failed, outer, inner", err.Error())
+		assert.Equal(t, "outer, inner", inner.Error())
+	})
+
+	t.Run("no wrapped error", func(t *testing.T) {
+		err := Errorf("invalid id %q, 100%% wrong", "x")
+		assert.Equal(t, `invalid id "x", 100% wrong`, err.Error())
+		assert.Nil(t, err.Next())
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Errorf.func6", err.Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("only wrapped error", func(t *testing.T) {
+		errSentinel := NewSentinel("not found")
+
+		err := Errorf("%w", errSentinel)
+		assert.Equal(t, "not found", err.Error())
+		assert.ErrorIs(t, err, errSentinel)
+		assert.Nil(t, err.Next())
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Errorf.func7", err.Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("nil wrapped error", func(t *testing.T) {
+		err := Errorf("lookup: %w", nil)
+		assert.Equal(t, "lookup: %!w(<nil>)", err.Error())
+		assert.Nil(t, err.Next())
+	})
+
+	t.Run("argument indexes", func(t *testing.T) {
+		err := Errorf("%[2]s: %[1]w", io.EOF, "reading")
+		assert.Equal(t, "reading: EOF", err.Error())
+		assert.True(t, errors.Is(err, io.EOF))
+	})
+}
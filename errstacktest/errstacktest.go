@@ -0,0 +1,199 @@
+// Package errstacktest helps asserting on errstack errors in tests. It normalizes stack traces and formatted
+// output so that they don't depend on the machine, the Go version or unrelated edits, and compares them with
+// golden files, which are rewritten when the tests run with -errstacktest.update.
+package errstacktest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nnishant776/errstack"
+)
+
+// update is namespaced, since the test packages importing errstacktest often define their own -update flag
+var update = flag.Bool("errstacktest.update", false, "rewrite the golden files of errstacktest with the actual output")
+
+// DroppedFunctionPrefixes lists the prefixes of the functions whose frames are dropped by the normalization
+var DroppedFunctionPrefixes = []string{"runtime.", "testing."}
+
+var (
+	rePath       = regexp.MustCompile(`(@|^|[\s;])(/|[A-Za-z]:[\\/])[^\s@;:]*[\\/]([^\s@;:\\/]+):`)
+	reLine       = regexp.MustCompile(`(\.(go|s)):\d+`)
+	reStackIndex = regexp.MustCompile(`#\d+: `)
+)
+
+// NormalizeStackTrace returns a copy of s without the runtime and testing frames, where every file is
+// reduced to its base name and the lines, program counters and entries are zeroed
+func NormalizeStackTrace(s errstack.StackTrace) errstack.StackTrace {
+	normalized := errstack.StackTrace{
+		Frames:     make([]errstack.Frame, 0, len(s.Frames)),
+		Truncated:  s.Truncated,
+		SampledOut: s.SampledOut,
+	}
+
+	for _, f := range s.Frames {
+		if dropped(f.Function) {
+			continue
+		}
+
+		normalized.Frames = append(normalized.Frames, errstack.Frame{
+			Function: f.Function,
+			File:     filepath.Base(f.File),
+			Message:  f.Message,
+		})
+	}
+
+	return normalized
+}
+
+// NormalizeOutput rewrites the output of the formatters like NormalizeStackTrace does: the runtime and
+// testing frames are dropped, the paths are reduced to their base name and the line numbers are replaced
+// with 0. The stack indices are dropped as well, since they depend on the number of dropped frames.
+func NormalizeOutput(s string) string {
+	return reStackIndex.ReplaceAllString(NormalizeOutputIndexed(s), "")
+}
+
+// NormalizeOutputIndexed normalizes s like NormalizeOutput but leaves the stack indices alone, for asserting on
+// the output of the formats which print them. The indices still count the dropped frames.
+func NormalizeOutputIndexed(s string) string {
+	if len(DroppedFunctionPrefixes) > 0 {
+		prefixes := make([]string, 0, len(DroppedFunctionPrefixes))
+		for _, prefix := range DroppedFunctionPrefixes {
+			prefixes = append(prefixes, regexp.QuoteMeta(prefix))
+		}
+
+		// A frame ends with its location, if any, so that the separator of the next chain element is kept
+		reDroppedFrame := regexp.MustCompile(`(;|\n)[ \t]*(#\d+: )?(` + strings.Join(prefixes, "|") + `)[^;\n,@]*(@[^;\n]*?:\d+)?`)
+		s = reDroppedFrame.ReplaceAllString(s, "")
+	}
+
+	s = rePath.ReplaceAllString(s, "$1$3:")
+	s = reLine.ReplaceAllString(s, "$1:0")
+
+	return s
+}
+
+// Sprintf formats the arguments with fmt.Sprintf and normalizes the output with NormalizeOutput
+func Sprintf(format string, args ...any) string {
+	return NormalizeOutput(fmt.Sprintf(format, args...))
+}
+
+// SprintfIndexed formats the arguments with fmt.Sprintf and normalizes the output with NormalizeOutputIndexed
+func SprintfIndexed(format string, args ...any) string {
+	return NormalizeOutputIndexed(fmt.Sprintf(format, args...))
+}
+
+// Golden compares got with the content of testdata/<name>.golden. With -errstacktest.update, the file is
+// rewritten with got instead.
+func Golden(t testing.TB, name string, got string) bool {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("errstacktest: creating golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("errstacktest: writing golden file: %v", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("errstacktest: reading golden file, run the test with -errstacktest.update to create it: %v", err)
+		return false
+	}
+
+	if string(want) != got {
+		t.Errorf("errstacktest: output differs from %s, run the test with -errstacktest.update to accept it\n--- want\n%s\n--- got\n%s", path, want, got)
+		return false
+	}
+
+	return true
+}
+
+// Functions returns the functions of the frames of err and of all the errors it holds: the elements of a
+// chain, the members of a joined error and the wrapped errors
+func Functions(err error) []string {
+	functions := []string{}
+
+	walk(err, func(s errstack.StackTrace) {
+		for _, f := range s.Frames {
+			functions = append(functions, f.Function)
+		}
+	})
+
+	return functions
+}
+
+// AssertTraceContains reports an error unless a frame of err is in fn. The function can be given with its
+// full import path, e.g. "github.com/x/y.Func", or with the last element only, e.g. "y.Func".
+func AssertTraceContains(t testing.TB, err error, fn string) bool {
+	t.Helper()
+
+	functions := Functions(err)
+	for _, f := range functions {
+		if matches(f, fn) {
+			return true
+		}
+	}
+
+	t.Errorf("errstacktest: no frame of %q in the stack trace:\n\t%s", fn, strings.Join(functions, "\n\t"))
+	return false
+}
+
+// AssertTraceNotContains reports an error if a frame of err is in fn, given as for AssertTraceContains
+func AssertTraceNotContains(t testing.TB, err error, fn string) bool {
+	t.Helper()
+
+	for _, f := range Functions(err) {
+		if matches(f, fn) {
+			t.Errorf("errstacktest: unexpected frame of %q in the stack trace", fn)
+			return false
+		}
+	}
+
+	return true
+}
+
+func walk(err error, visit func(errstack.StackTrace)) {
+	switch e := err.(type) {
+	case nil:
+	case errstack.ChainedError:
+		for elem := e; elem != nil; elem = elem.Next() {
+			if elem.Inner() != nil {
+				visit(elem.Inner().StackTrace())
+			}
+		}
+	case errstack.StackTracer:
+		visit(e.StackTrace())
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			walk(inner, visit)
+		}
+	default:
+		walk(errors.Unwrap(err), visit)
+	}
+}
+
+func matches(function, fn string) bool {
+	return function == fn || strings.HasSuffix(function, "/"+fn)
+}
+
+func dropped(function string) bool {
+	for _, prefix := range DroppedFunctionPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package errstacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/nnishant776/errstack"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (self *recordingT) Helper() {}
+
+func (self *recordingT) Errorf(format string, args ...any) {
+	self.errors = append(self.errors, fmt.Sprintf(format, args...))
+}
+
+func loadProfile() error {
+	return errstack.NewChainString("loading profile", errstack.WithStack()).Chain(queryUser())
+}
+
+func queryUser() error {
+	return errstack.NewString("user not found", errstack.WithStack())
+}
+
+func Test_NormalizeStackTrace(t *testing.T) {
+	stackTrace := errstack.StackTrace{
+		Frames: []errstack.Frame{
+			{Function: "github.com/acme/app.Run", File: "/home/ci/src/app/run.go", Line: 42, PC: 0x4a2f31},
+			{Function: "testing.tRunner", File: "/usr/local/go/src/testing/testing.go", Line: 1690},
+			{Function: "runtime.goexit", File: "/usr/local/go/src/runtime/asm_amd64.s", Line: 1700},
+		},
+		Truncated: true,
+	}
+
+	assert.Equal(t, errstack.StackTrace{
+		Frames:    []errstack.Frame{{Function: "github.com/acme/app.Run", File: "run.go"}},
+		Truncated: true,
+	}, NormalizeStackTrace(stackTrace))
+}
+
+func Test_NormalizeOutput(t *testing.T) {
+	cases := map[string]string{
+		"boom=>github.com/acme/app.Run@/home/ci/src/app/run.go:42;testing.tRunner@/usr/local/go/src/testing/testing.go:1690;runtime.goexit@/usr/local/go/src/runtime/asm_amd64.s:1700": "boom=>github.com/acme/app.Run@run.go:0",
+		"boom\n  #2: github.com/acme/app.Run@C:/src/app/run.go:42\n  #1: testing.tRunner@/go/src/testing/testing.go:1690\n  #0: runtime.goexit@/go/src/runtime/asm_amd64.s:1700":       "boom\n  github.com/acme/app.Run@run.go:0",
+		"boom": "boom",
+		"a=>x.F@/src/x/a.go:3;testing.tRunner@/go/src/testing/testing.go:1690;runtime.goexit@/go/src/runtime/asm_amd64.s:1700, b=>x.G@/src/x/b.go:5;runtime.goexit@/go/src/runtime/asm_amd64.s:1700": "a=>x.F@a.go:0, b=>x.G@b.go:0",
+		"a=>x.F;testing.tRunner;runtime.goexit, b=>x.G;runtime.goexit": "a=>x.F, b=>x.G",
+	}
+
+	for in, expect := range cases {
+		assert.Equal(t, expect, NormalizeOutput(in))
+	}
+
+	assert.Equal(
+		t,
+		"boom\n  #2: github.com/acme/app.Run@run.go:0",
+		NormalizeOutputIndexed("boom\n  #2: github.com/acme/app.Run@C:/src/app/run.go:42\n  #1: testing.tRunner@/go/src/testing/testing.go:1690\n  #0: runtime.goexit@/go/src/runtime/asm_amd64.s:1700"),
+	)
+}
+
+func Test_Golden(t *testing.T) {
+	err := loadProfile()
+
+	Golden(t, "chain", Sprintf("%+v", err))
+	b, _ := json.MarshalIndent(NormalizeStackTrace(err.(errstack.ChainedError).Next().Inner().StackTrace()), "", "  ")
+	Golden(t, "trace_json", string(b))
+
+	if *update {
+		return
+	}
+
+	rec := &recordingT{}
+	assert.False(t, Golden(rec, "chain", "something else"))
+	assert.False(t, Golden(rec, "missing", ""))
+	assert.Len(t, rec.errors, 2)
+}
+
+func Test_AssertTrace(t *testing.T) {
+	err := loadProfile()
+
+	AssertTraceContains(t, err, "errstacktest.queryUser")
+	AssertTraceContains(t, err, "github.com/nnishant776/errstack/errstacktest.loadProfile")
+	AssertTraceContains(t, fmt.Errorf("wrapped: %w", err), "errstacktest.queryUser")
+	AssertTraceNotContains(t, err, "errstacktest.Test_Golden")
+
+	rec := &recordingT{}
+	assert.False(t, AssertTraceContains(rec, err, "errstacktest.Test_Golden"))
+	assert.False(t, AssertTraceNotContains(rec, err, "errstacktest.queryUser"))
+	assert.False(t, AssertTraceContains(rec, nil, "errstacktest.queryUser"))
+	assert.Len(t, rec.errors, 3)
+}
@@ -0,0 +1,162 @@
+package errstack
+
+import (
+	"hash"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+type FingerprintOptions struct {
+	// IncludeLocation hashes the file and the line of every frame along with the function name
+	IncludeLocation bool
+	// IgnoreLines leaves the line out of the hashed location, so that edits elsewhere in a file keep the
+	// fingerprint stable
+	IgnoreLines bool
+	// MaxFrames is the number of innermost frames hashed for every error. A value <= 0 hashes all of them.
+	MaxFrames int
+	// InJSON adds the fingerprint of the error to the output of MarshalJSON
+	InJSON bool
+}
+
+// DefaultFingerprintOptions are the options used by Fingerprint and by the Fingerprint methods
+var DefaultFingerprintOptions = FingerprintOptions{}
+
+// FingerprintGroup holds the errors sharing a fingerprint
+type FingerprintGroup struct {
+	Fingerprint string
+	Errors      []error
+}
+
+func (self *StacktraceError) Fingerprint() string {
+	return FingerprintWithOptions(self, DefaultFingerprintOptions)
+}
+
+func (self *ChainedStacktraceError) Fingerprint() string {
+	return FingerprintWithOptions(self, DefaultFingerprintOptions)
+}
+
+// Fingerprint returns a stable hash identifying the site of err, computed with DefaultFingerprintOptions
+func Fingerprint(err error) string {
+	return FingerprintWithOptions(err, DefaultFingerprintOptions)
+}
+
+// FingerprintWithOptions hashes the normalized function names of the stack trace of every element of err.
+// The message of an element is hashed instead when it has no stack trace. The fingerprint of a nil error is
+// empty.
+func FingerprintWithOptions(err error, opts FingerprintOptions) string {
+	if err == nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+
+	chErr, ok := err.(ChainedError)
+	if !ok {
+		fingerprintElem(h, err, opts)
+	} else {
+		for elem := chErr; elem != nil; elem = elem.Next() {
+			if elem.Inner() != nil {
+				fingerprintElem(h, elem.Inner(), opts)
+			}
+		}
+	}
+
+	sum := [16]byte{}
+	fp := strconv.AppendUint(sum[:0], h.Sum64(), 16)
+
+	return strings.Repeat("0", 16-len(fp)) + string(fp)
+}
+
+// GroupByFingerprint groups errs by their fingerprint. The groups, and the errors within a group, keep the
+// order in which they appear in errs. Nil errors are skipped.
+func GroupByFingerprint(errs []error, opts FingerprintOptions) []FingerprintGroup {
+	groups := ([]FingerprintGroup)(nil)
+	index := make(map[string]int, len(errs))
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		fp := FingerprintWithOptions(err, opts)
+		i, ok := index[fp]
+		if !ok {
+			i = len(groups)
+			index[fp] = i
+			groups = append(groups, FingerprintGroup{Fingerprint: fp})
+		}
+
+		groups[i].Errors = append(groups[i].Errors, err)
+	}
+
+	return groups
+}
+
+func fingerprintElem(h hash.Hash64, err error, opts FingerprintOptions) {
+	frames := ([]Frame)(nil)
+	if stErr, ok := err.(StackTracer); ok {
+		frames = stErr.StackTrace().Frames
+	}
+
+	if opts.MaxFrames > 0 && len(frames) > opts.MaxFrames {
+		frames = frames[:opts.MaxFrames]
+	}
+
+	if len(frames) <= 0 {
+		h.Write([]byte(err.Error()))
+		h.Write([]byte{0})
+		return
+	}
+
+	num := [20]byte{}
+	for _, f := range frames {
+		h.Write([]byte(normalizeFunction(f.Function)))
+		if opts.IncludeLocation {
+			h.Write([]byte{'@'})
+			h.Write(string2Slice(f.File))
+			if !opts.IgnoreLines {
+				h.Write([]byte{':'})
+				h.Write(strconv.AppendInt(num[:0], int64(f.Line), 10))
+			}
+		}
+		h.Write([]byte{'\n'})
+	}
+
+	h.Write([]byte{0})
+}
+
+// normalizeFunction removes the parts of a function name which change without the code of the function
+// changing: the type arguments of generic functions and the numbering of closures, e.g.
+// "pkg.Map[...].func2.1" becomes "pkg.Map.func.func"
+func normalizeFunction(fn string) string {
+	if strings.IndexByte(fn, '[') >= 0 {
+		sb := strings.Builder{}
+		depth := 0
+		for _, r := range fn {
+			switch {
+			case r == '[':
+				depth++
+			case r == ']':
+				depth--
+			case depth == 0:
+				sb.WriteRune(r)
+			}
+		}
+		fn = sb.String()
+	}
+
+	pkg, name := splitFunction(fn)
+	if name == "" || !strings.Contains("."+name, ".func") {
+		return fn
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if strings.Trim(strings.TrimPrefix(part, "func"), "0123456789") == "" {
+			parts[i] = "func"
+		}
+	}
+
+	return pkg + "." + strings.Join(parts, ".")
+}
@@ -0,0 +1,101 @@
+package errstack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Fingerprint(t *testing.T) {
+	newErr := func(msg string) *StacktraceError {
+		return NewString(msg, WithStack())
+	}
+
+	t.Run("stable per site", func(t *testing.T) {
+		errs := []error{}
+		for i := 0; i < 2; i++ {
+			errs = append(errs, newErr("user not found"))
+		}
+		other := NewString("user not found", WithStack())
+
+		assert.Len(t, Fingerprint(errs[0]), 16)
+		assert.Equal(t, Fingerprint(errs[0]), Fingerprint(errs[1]))
+		assert.NotEqual(t, Fingerprint(errs[0]), Fingerprint(other))
+		assert.Equal(t, Fingerprint(errs[0]), errs[0].(*StacktraceError).Fingerprint())
+		assert.Equal(t, "", Fingerprint(nil))
+	})
+
+	t.Run("locations", func(t *testing.T) {
+		err := newErr("boom")
+		frames := append([]Frame(nil), err.StackTrace().Frames...)
+		frames[0].Line += 10
+		moved := NewFromStackTrace("boom", StackTrace{Frames: frames})
+
+		assert.Equal(t, Fingerprint(err), Fingerprint(moved))
+
+		opts := FingerprintOptions{IncludeLocation: true}
+		assert.NotEqual(t, FingerprintWithOptions(err, opts), FingerprintWithOptions(moved, opts))
+
+		opts.IgnoreLines = true
+		assert.Equal(t, FingerprintWithOptions(err, opts), FingerprintWithOptions(moved, opts))
+	})
+
+	t.Run("max frames", func(t *testing.T) {
+		opts := FingerprintOptions{MaxFrames: 1}
+		assert.Equal(t, FingerprintWithOptions(newErr("a"), opts), FingerprintWithOptions(NewString("b", WithStack()), opts))
+	})
+
+	t.Run("errors without trace", func(t *testing.T) {
+		assert.Equal(t, Fingerprint(NewString("a")), Fingerprint(NewString("a")))
+		assert.NotEqual(t, Fingerprint(NewString("a")), Fingerprint(NewString("b")))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		chErr := NewChain(newErr("outer")).Chain(newErr("inner"))
+		assert.NotEqual(t, Fingerprint(chErr.Inner()), Fingerprint(chErr))
+		assert.Equal(t, Fingerprint(chErr), chErr.(*ChainedStacktraceError).Fingerprint())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		defer func(opts FingerprintOptions) { DefaultFingerprintOptions = opts }(DefaultFingerprintOptions)
+
+		err := newErr("boom")
+		b, _ := json.Marshal(err)
+		assert.NotContains(t, string(b), `"fingerprint"`)
+
+		DefaultFingerprintOptions.InJSON = true
+		b, _ = json.Marshal(err)
+		assert.Contains(t, string(b), `"fingerprint":"`+err.Fingerprint()+`"`)
+	})
+
+	t.Run("grouping", func(t *testing.T) {
+		errs := []error{}
+		for i := 0; i < 3; i++ {
+			errs = append(errs, newErr("a"))
+		}
+		errs = append(errs, nil, NewString("b"))
+
+		groups := GroupByFingerprint(errs, DefaultFingerprintOptions)
+		if assert.Len(t, groups, 2) {
+			assert.Equal(t, errs[:3], groups[0].Errors)
+			assert.Equal(t, errs[4:], groups[1].Errors)
+			assert.Equal(t, Fingerprint(errs[4]), groups[1].Fingerprint)
+		}
+	})
+}
+
+func Test_NormalizeFunction(t *testing.T) {
+	cases := map[string]string{
+		"main.main":                              "main.main",
+		"github.com/x/y.(*T).Method":             "github.com/x/y.(*T).Method",
+		"github.com/x/y.Run.func2.1":             "github.com/x/y.Run.func.func",
+		"github.com/x/y.Map[...].func1":          "github.com/x/y.Map.func",
+		"github.com/x/y.Map[github.com/z/w.T]":   "github.com/x/y.Map",
+		"github.com/x/y.(*List[go.shape.int]).F": "github.com/x/y.(*List).F",
+	}
+
+	for fn, expect := range cases {
+		assert.Equal(t, expect, normalizeFunction(fn), fn)
+	}
+}
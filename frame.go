@@ -1,7 +1,14 @@
 package errstack
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -9,9 +16,28 @@ const (
 )
 
 type Frame struct {
-	Function string `json:"function"`
-	File     string `json:"file"`
-	Line     string `json:"line"`
+	Function string
+	File     string
+	Line     int
+	// PC is the program counter of the location. It is zero for frames which weren't symbolized in this
+	// process.
+	PC uintptr
+	// Entry is the entry address of the function, if known
+	Entry uintptr
+	// Inlined reports whether the function was inlined into its caller
+	Inlined bool
+	// Message is the annotation added by a throw at this frame, if any
+	Message string
+}
+
+type frameJSON struct {
+	Function string          `json:"function"`
+	File     string          `json:"file"`
+	Line     json.RawMessage `json:"line"`
+	PC       uintptr         `json:"pc,omitempty"`
+	Entry    uintptr         `json:"entry,omitempty"`
+	Inlined  bool            `json:"inlined,omitempty"`
+	Message  string          `json:"message,omitempty"`
 }
 
 func (self Frame) String() string {
@@ -19,3 +45,168 @@ func (self Frame) String() string {
 	DefaultStackFrameFormatter.FormatBuffer(&sb, self)
 	return sb.String()
 }
+
+// MarshalJSON encodes the line as a string, as done by the earlier versions of Frame. The program counter
+// and the entry address are only encoded when the options of DefaultStackFrameFormatter show them.
+func (self Frame) MarshalJSON() ([]byte, error) {
+	payload := frameJSON{
+		Function: self.Function,
+		File:     self.File,
+		Line:     strconv.AppendQuote(nil, strconv.Itoa(self.Line)),
+		Inlined:  self.Inlined,
+		Message:  self.Message,
+	}
+
+	opts := DefaultStackFrameFormatter.Options()
+	if opts.ShowPC {
+		payload.PC = self.PC
+	}
+	if opts.ShowEntry {
+		payload.Entry = self.Entry
+	}
+
+	return json.Marshal(payload)
+}
+
+// UnmarshalJSON accepts the line either as a number or as a string. The program counter and the entry
+// address aren't restored, since they only make sense in the process which encoded the frame.
+func (self *Frame) UnmarshalJSON(data []byte) error {
+	if self == nil {
+		return errNilUnmarshalTarget
+	}
+
+	payload := frameJSON{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	line := 0
+	if raw := bytes.TrimSpace(payload.Line); len(raw) > 0 && string(raw) != "null" {
+		if raw[0] == '"' {
+			str := ""
+			if err := json.Unmarshal(raw, &str); err != nil {
+				return err
+			}
+			raw = []byte(str)
+		}
+
+		if len(raw) > 0 {
+			n, err := strconv.Atoi(string(raw))
+			if err != nil {
+				return fmt.Errorf("errstack: invalid frame line %s: %w", payload.Line, err)
+			}
+			line = n
+		}
+	}
+
+	*self = Frame{
+		Function: payload.Function,
+		File:     payload.File,
+		Line:     line,
+		Inlined:  payload.Inlined,
+		Message:  payload.Message,
+	}
+
+	return nil
+}
+
+// Package returns the import path of the package of the function, e.g. "github.com/x/y" for the function
+// "github.com/x/y.(*T).Method"
+func (self Frame) Package() string {
+	return funcPackage(self.Function)
+}
+
+// ShortFunction returns the name of the function without its package path, e.g. "(*T).Method" for the
+// function "github.com/x/y.(*T).Method"
+func (self Frame) ShortFunction() string {
+	_, name := splitFunction(self.Function)
+	if name == "" {
+		return self.Function
+	}
+
+	return name
+}
+
+func funcPackage(fn string) string {
+	pkg, _ := splitFunction(fn)
+	return pkg
+}
+
+// splitFunction splits a function name into the import path of its package and the name of the function
+// within the package. The compiler escapes the dots of the last element of the import path as "%2e", but
+// names read from other sources, like "gopkg.in/yaml.v3.Unmarshal", aren't escaped. For those, the modules
+// of the binary and the ".vN" suffixes of gopkg.in are used to tell the dots of the path apart.
+func splitFunction(fn string) (string, string) {
+	start := strings.LastIndexByte(fn, '/') + 1
+	if mod := dottedModule(fn); mod != "" {
+		start = len(mod)
+	} else {
+		for {
+			dot := strings.IndexByte(fn[start:], '.')
+			if dot < 0 || !isVersionSuffix(fn[start+dot+1:]) {
+				break
+			}
+			start += dot + 1
+		}
+	}
+
+	dot := strings.IndexByte(fn[start:], '.')
+	if dot < 0 {
+		return unescapePath(fn), ""
+	}
+
+	return unescapePath(fn[:start+dot]), fn[start+dot+1:]
+}
+
+var dottedModules = sync.OnceValue(func() []string {
+	mods := []string{}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return mods
+	}
+
+	for _, mod := range append([]*debug.Module{&info.Main}, info.Deps...) {
+		if strings.IndexByte(path.Base(mod.Path), '.') >= 0 {
+			mods = append(mods, mod.Path)
+		}
+	}
+
+	return mods
+})
+
+// dottedModule returns the longest module of the binary, whose last path element has a dot, which the
+// package of fn is the root of
+func dottedModule(fn string) string {
+	match := ""
+	for _, mod := range dottedModules() {
+		if len(mod) > len(match) && len(fn) > len(mod) && fn[len(mod)] == '.' && strings.HasPrefix(fn, mod) {
+			match = mod
+		}
+	}
+
+	return match
+}
+
+// isVersionSuffix reports whether s starts with a major version element like "v3.", as in "gopkg.in/yaml.v3"
+func isVersionSuffix(s string) bool {
+	if len(s) < 3 || s[0] != 'v' {
+		return false
+	}
+
+	i := 1
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	return i > 1 && i < len(s) && s[i] == '.'
+}
+
+// unescapePath reverts the escaping of the dots done by the compiler, which happens twice for some closures
+func unescapePath(pkg string) string {
+	if strings.IndexByte(pkg, '%') < 0 {
+		return pkg
+	}
+
+	return strings.ReplaceAll(strings.ReplaceAll(pkg, "%252e", "."), "%2e", ".")
+}
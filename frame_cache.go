@@ -0,0 +1,114 @@
+package errstack
+
+import (
+	"sync"
+)
+
+// DefaultFrameCacheSize is the number of program counters whose frames are kept by the process-wide frame
+// cache, unless changed with SetFrameCacheSize
+const DefaultFrameCacheSize = 4096
+
+var pcFrameCache = &frameCache{
+	size:   DefaultFrameCacheSize,
+	frames: make(map[uintptr][]Frame, 64),
+}
+
+// SetFrameCacheSize changes the number of program counters whose frames are cached and drops the cached
+// frames. A size <= 0 disables the cache.
+func SetFrameCacheSize(size int) {
+	pcFrameCache.reset(size)
+}
+
+// frameCache maps a program counter to the frames it symbolizes to, so that the frames of a call site are
+// resolved and their strings are allocated only once for the whole process. When the cache is full, an
+// arbitrary entry is evicted.
+type frameCache struct {
+	mu     sync.RWMutex
+	size   int
+	frames map[uintptr][]Frame
+}
+
+func (self *frameCache) enabled() bool {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	return self.size > 0
+}
+
+func (self *frameCache) lookup(pc uintptr) ([]Frame, bool) {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	frames, ok := self.frames[pc]
+	return frames, ok
+}
+
+func (self *frameCache) store(pc uintptr, frames []Frame) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.size <= 0 {
+		return
+	}
+
+	if _, ok := self.frames[pc]; !ok && len(self.frames) >= self.size {
+		for k := range self.frames {
+			delete(self.frames, k)
+			break
+		}
+	}
+
+	self.frames[pc] = frames
+}
+
+func (self *frameCache) len() int {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	return len(self.frames)
+}
+
+func (self *frameCache) clear() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.frames = make(map[uintptr][]Frame, min(max(0, self.size), 64))
+}
+
+func (self *frameCache) reset(size int) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.size = size
+	self.frames = make(map[uintptr][]Frame, min(max(0, size), 64))
+}
+
+func genStackTraceFromPCs(pcs []uintptr) []Frame {
+	if len(pcs) <= 0 {
+		return nil
+	}
+
+	if !pcFrameCache.enabled() {
+		return capturer().Symbolize(pcs)
+	}
+
+	frames := make([]Frame, 0, len(pcs))
+
+	for i, pc := range pcs {
+		pcFrames, ok := pcFrameCache.lookup(pc)
+		if !ok {
+			pcFrames = capturer().Symbolize(pcs[i : i+1])
+			pcFrameCache.store(pc, pcFrames)
+		}
+
+		// The program counter following runtime.sigpanic is the faulting instruction rather than a return
+		// address, which the runtime only accounts for when it symbolizes both of them together
+		if n := len(pcFrames); n > 0 && pcFrames[n-1].Function == "runtime.sigpanic" && i+1 < len(pcs) {
+			return append(frames, capturer().Symbolize(pcs[i:])...)
+		}
+
+		frames = append(frames, pcFrames...)
+	}
+
+	return frames
+}
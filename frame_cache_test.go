@@ -0,0 +1,50 @@
+package errstack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FrameCache(t *testing.T) {
+	defer SetFrameCacheSize(DefaultFrameCacheSize)
+
+	pcs := callersPCs(0, _MAX_CALL_DEPTH)
+	uncached := RuntimeCapturer{}.Symbolize(pcs)
+
+	t.Run("cached frames match the runtime", func(t *testing.T) {
+		SetFrameCacheSize(DefaultFrameCacheSize)
+
+		assert.Equal(t, uncached, genStackTraceFromPCs(pcs))
+		assert.Equal(t, len(pcs), pcFrameCache.len())
+		assert.Equal(t, uncached, genStackTraceFromPCs(pcs))
+	})
+
+	t.Run("bounded size", func(t *testing.T) {
+		SetFrameCacheSize(2)
+
+		assert.Equal(t, uncached, genStackTraceFromPCs(pcs))
+		assert.Equal(t, 2, pcFrameCache.len())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		SetFrameCacheSize(0)
+
+		assert.Equal(t, uncached, genStackTraceFromPCs(pcs))
+		assert.Equal(t, 0, pcFrameCache.len())
+	})
+
+	t.Run("concurrent symbolization", func(t *testing.T) {
+		SetFrameCacheSize(DefaultFrameCacheSize)
+
+		err := NewString("shared", WithStack())
+		done := make(chan StackTrace)
+		for i := 0; i < 8; i++ {
+			go func() { done <- err.StackTraceN(3) }()
+		}
+
+		for i := 0; i < 8; i++ {
+			assert.Equal(t, err.StackTrace().Frames[:3], (<-done).Frames)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package errstack
+
+import (
+	"path"
+	"strings"
+)
+
+type FrameFilter interface {
+	Include(f Frame) bool
+}
+
+var _ FrameFilter = FrameFilterFunc(nil)
+var _ FrameFilter = FrameFilterRules{}
+
+type FrameFilterFunc func(f Frame) bool
+
+func (self FrameFilterFunc) Include(f Frame) bool {
+	return self(f)
+}
+
+// FrameFilterRules is a FrameFilter built from a set of include and exclude rules. When any include rule
+// is present, a frame must match at least one of them to be kept. A frame matching any exclude rule is
+// always dropped. Packages ending with "/..." also match their sub-packages and file globs without a '/'
+// are matched against the base name of the file.
+type FrameFilterRules struct {
+	IncludeFunctionPrefixes []string
+	ExcludeFunctionPrefixes []string
+	IncludePackages         []string
+	ExcludePackages         []string
+	IncludeFileGlobs        []string
+	ExcludeFileGlobs        []string
+}
+
+func (self FrameFilterRules) Include(f Frame) bool {
+	hasInclude := len(self.IncludeFunctionPrefixes) > 0 ||
+		len(self.IncludePackages) > 0 ||
+		len(self.IncludeFileGlobs) > 0
+
+	if hasInclude && !self.match(f, self.IncludeFunctionPrefixes, self.IncludePackages, self.IncludeFileGlobs) {
+		return false
+	}
+
+	return !self.match(f, self.ExcludeFunctionPrefixes, self.ExcludePackages, self.ExcludeFileGlobs)
+}
+
+func (self FrameFilterRules) match(f Frame, funcPrefixes, pkgs, fileGlobs []string) bool {
+	for _, prefix := range funcPrefixes {
+		if strings.HasPrefix(f.Function, prefix) {
+			return true
+		}
+	}
+
+	if len(pkgs) > 0 {
+		pkg := funcPackage(f.Function)
+		for _, p := range pkgs {
+			if prefix, ok := strings.CutSuffix(p, "/..."); ok {
+				if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+					return true
+				}
+			} else if pkg == p {
+				return true
+			}
+		}
+	}
+
+	for _, glob := range fileGlobs {
+		file := f.File
+		if !strings.Contains(glob, "/") {
+			file = path.Base(file)
+		}
+
+		if ok, _ := path.Match(glob, file); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Filter returns a copy of the stack trace with only the frames accepted by filter. A nil filter keeps
+// every frame.
+func (self StackTrace) Filter(filter FrameFilter) StackTrace {
+	if filter == nil || len(self.Frames) <= 0 {
+		return self
+	}
+
+	frames := make([]Frame, 0, len(self.Frames))
+	for _, f := range self.Frames {
+		if filter.Include(f) {
+			frames = append(frames, f)
+		}
+	}
+
+	return StackTrace{
+		Frames:     frames,
+		Truncated:  self.Truncated,
+		SampledOut: self.SampledOut,
+	}
+}
@@ -0,0 +1,92 @@
+package errstack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FrameFilter(t *testing.T) {
+	stackTrace := StackTrace{
+		Frames: []Frame{
+			{Function: "github.com/acme/app/db.(*Conn).Query", File: "/src/app/db/conn.go", Line: 42},
+			{Function: "github.com/acme/app/handler.Get", File: "/src/app/handler/get.go", Line: 17},
+			{Function: "net/http.HandlerFunc.ServeHTTP", File: "/go/src/net/http/server.go", Line: 2136},
+			{Function: "net/http.serverHandler.ServeHTTP", File: "/go/src/net/http/server.go", Line: 2938},
+			{Function: "github.com/acme/app/vendor/mux.(*Router).ServeHTTP", File: "/src/app/vendor/mux/mux.go", Line: 212},
+			{Function: "net/http.(*conn).serve", File: "/go/src/net/http/server.go", Line: 2009},
+			{Function: "runtime.goexit", File: "/go/src/runtime/asm_amd64.s", Line: 1650},
+		},
+	}
+
+	functions := func(s StackTrace) []string {
+		names := []string{}
+		for _, f := range s.Frames {
+			names = append(names, f.Function)
+		}
+		return names
+	}
+
+	t.Run("exclude rules", func(t *testing.T) {
+		filter := FrameFilterRules{
+			ExcludeFunctionPrefixes: []string{"runtime."},
+			ExcludePackages:         []string{"net/http", "github.com/acme/app/vendor/..."},
+		}
+
+		assert.Equal(t, []string{
+			"github.com/acme/app/db.(*Conn).Query",
+			"github.com/acme/app/handler.Get",
+		}, functions(stackTrace.Filter(filter)))
+	})
+
+	t.Run("include rules", func(t *testing.T) {
+		filter := FrameFilterRules{
+			IncludeFileGlobs: []string{"/src/app/*/*.go"},
+			ExcludeFileGlobs: []string{"conn.go"},
+		}
+
+		assert.Equal(t, []string{
+			"github.com/acme/app/handler.Get",
+		}, functions(stackTrace.Filter(filter)))
+	})
+
+	t.Run("function filter", func(t *testing.T) {
+		filter := FrameFilterFunc(func(f Frame) bool { return f.Line == 42 })
+		assert.Equal(t, []string{"github.com/acme/app/db.(*Conn).Query"}, functions(stackTrace.Filter(filter)))
+		assert.Equal(t, stackTrace, stackTrace.Filter(nil))
+	})
+
+	t.Run("flags kept", func(t *testing.T) {
+		flagged := stackTrace
+		flagged.Truncated, flagged.SampledOut = true, true
+
+		filtered := flagged.Filter(FrameFilterRules{ExcludeFunctionPrefixes: []string{"runtime."}})
+		assert.True(t, filtered.Truncated)
+		assert.True(t, filtered.SampledOut)
+	})
+
+	t.Run("elided frames in formatter", func(t *testing.T) {
+		stFmt := DefaultStackTraceFormatter.Clone()
+		sOpts := stFmt.Options()
+		sOpts.Filter = FrameFilterRules{ExcludePackages: []string{"net/http", "runtime"}}
+		stFmt.SetOptions(sOpts)
+		stFmt.FrameFormatter().SetOptions(FrameFormatterOptions{SkipLocation: true})
+
+		assert.Equal(
+			t,
+			"#6: github.com/acme/app/db.(*Conn).Query;#5: github.com/acme/app/handler.Get;... 2 frames elided;"+
+				"#2: github.com/acme/app/vendor/mux.(*Router).ServeHTTP;... 2 frames elided",
+			stFmt.Format(stackTrace),
+		)
+
+		sOpts.ElisionPrefix, sOpts.ElisionSuffix, sOpts.SkipStackIndex = "", "", true
+		stFmt.SetOptions(sOpts)
+
+		assert.Equal(
+			t,
+			"github.com/acme/app/db.(*Conn).Query;github.com/acme/app/handler.Get;"+
+				"github.com/acme/app/vendor/mux.(*Router).ServeHTTP",
+			stFmt.Format(stackTrace),
+		)
+	})
+}
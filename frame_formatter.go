@@ -2,6 +2,7 @@ package errstack
 
 import (
 	"io"
+	"strconv"
 	"strings"
 )
 
@@ -9,8 +10,24 @@ type FrameFormatterOptions struct {
 	LocationPrefix    string
 	LocationSuffix    string
 	FileLineSeparator string
+	PathTrimmer       PathTrimmer
 	SkipFunctionName  bool
 	SkipLocation      bool
+	// ShowPC appends the program counter in hex after PCPrefix, for frames which have one
+	ShowPC   bool
+	PCPrefix string
+	// ShowEntry appends the entry address of the function in hex after EntryPrefix, for frames which have
+	// one
+	ShowEntry   bool
+	EntryPrefix string
+	// ShowInlined appends InlinedMarker to the frames of inlined functions
+	ShowInlined   bool
+	InlinedMarker string
+	// SkipMessage omits the annotations added by the throws, which are otherwise written between
+	// MessagePrefix and MessageSuffix
+	SkipMessage   bool
+	MessagePrefix string
+	MessageSuffix string
 }
 
 type FrameFormatter interface {
@@ -33,6 +50,8 @@ func (self *frameFormatter) format(w io.Writer, f Frame) {
 		return
 	}
 
+	num := [20]byte{}
+
 	if !self.opts.SkipFunctionName {
 		switch o := w.(type) {
 		case io.StringWriter:
@@ -43,6 +62,10 @@ func (self *frameFormatter) format(w io.Writer, f Frame) {
 	}
 
 	if !self.opts.SkipLocation {
+		if self.opts.PathTrimmer != nil {
+			f.File = self.opts.PathTrimmer.TrimPath(f)
+		}
+
 		switch o := w.(type) {
 		case io.StringWriter:
 			if !self.opts.SkipFunctionName {
@@ -50,7 +73,7 @@ func (self *frameFormatter) format(w io.Writer, f Frame) {
 			}
 			o.WriteString(f.File)
 			o.WriteString(self.opts.FileLineSeparator)
-			o.WriteString(f.Line)
+			w.Write(strconv.AppendInt(num[:0], int64(f.Line), 10))
 			if !self.opts.SkipFunctionName {
 				o.WriteString(self.opts.LocationSuffix)
 			}
@@ -60,12 +83,32 @@ func (self *frameFormatter) format(w io.Writer, f Frame) {
 			}
 			w.Write(string2Slice(f.File))
 			w.Write(string2Slice(self.opts.FileLineSeparator))
-			w.Write(string2Slice(f.Line))
+			w.Write(strconv.AppendInt(num[:0], int64(f.Line), 10))
 			if !self.opts.SkipFunctionName {
 				w.Write(string2Slice(self.opts.LocationSuffix))
 			}
 		}
 	}
+
+	if self.opts.ShowPC && f.PC != 0 {
+		w.Write(string2Slice(self.opts.PCPrefix))
+		w.Write(strconv.AppendUint(num[:0], uint64(f.PC), 16))
+	}
+
+	if self.opts.ShowEntry && f.Entry != 0 {
+		w.Write(string2Slice(self.opts.EntryPrefix))
+		w.Write(strconv.AppendUint(num[:0], uint64(f.Entry), 16))
+	}
+
+	if self.opts.ShowInlined && f.Inlined {
+		w.Write(string2Slice(self.opts.InlinedMarker))
+	}
+
+	if !self.opts.SkipMessage && f.Message != "" {
+		w.Write(string2Slice(self.opts.MessagePrefix))
+		w.Write(string2Slice(f.Message))
+		w.Write(string2Slice(self.opts.MessageSuffix))
+	}
 }
 
 func (self *frameFormatter) Options() FrameFormatterOptions {
@@ -0,0 +1,88 @@
+package errstack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FrameJSON(t *testing.T) {
+	t.Run("line encoded as string", func(t *testing.T) {
+		b, err := json.Marshal(Frame{Function: "main.main", File: "/src/main.go", Line: 42})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"function":"main.main","file":"/src/main.go","line":"42"}`, string(b))
+	})
+
+	t.Run("line decoded from string or number", func(t *testing.T) {
+		for _, data := range []string{
+			`{"function":"main.main","file":"/src/main.go","line":"42"}`,
+			`{"function":"main.main","file":"/src/main.go","line":42}`,
+		} {
+			f := Frame{}
+			assert.NoError(t, json.Unmarshal([]byte(data), &f))
+			assert.Equal(t, Frame{Function: "main.main", File: "/src/main.go", Line: 42}, f)
+		}
+
+		assert.Error(t, json.Unmarshal([]byte(`{"line":"forty-two"}`), &Frame{}))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		stackTrace := NewString("round trip", WithStack()).StackTrace()
+		b, err := json.Marshal(stackTrace)
+		assert.NoError(t, err)
+
+		decoded := StackTrace{}
+		assert.NoError(t, json.Unmarshal(b, &decoded))
+		assert.NotZero(t, stackTrace.Frames[0].PC)
+		assert.Equal(t, withoutAddresses(stackTrace), decoded)
+	})
+
+	t.Run("addresses on demand", func(t *testing.T) {
+		f := Frame{Function: "main.main", File: "/src/main.go", Line: 42, PC: 0x4a2f31, Entry: 0x4a2f00}
+
+		b, err := json.Marshal(f)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"function":"main.main","file":"/src/main.go","line":"42"}`, string(b))
+
+		prev := DefaultStackFrameFormatter
+		defer func() { DefaultStackFrameFormatter = prev }()
+
+		opts := prev.Options()
+		opts.ShowPC, opts.ShowEntry = true, true
+		DefaultStackFrameFormatter = prev.Copy().SetOptions(opts)
+
+		b, err = json.Marshal(f)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"function":"main.main","file":"/src/main.go","line":"42","pc":4861745,"entry":4861696}`, string(b))
+
+		decoded := Frame{}
+		assert.NoError(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, Frame{Function: "main.main", File: "/src/main.go", Line: 42}, decoded)
+	})
+}
+
+func Test_FrameFormatterOptions(t *testing.T) {
+	f := Frame{Function: "main.run", File: "/src/main.go", Line: 7, PC: 0x4a2f31, Entry: 0x4a2f00, Inlined: true}
+
+	opts := DefaultStackFrameFormatter.Options()
+	assert.Equal(t, "main.run@/src/main.go:7", DefaultStackFrameFormatter.Copy().SetOptions(opts).Format(f))
+
+	opts.ShowPC = true
+	opts.ShowEntry = true
+	opts.ShowInlined = true
+	assert.Equal(t, "main.run@/src/main.go:7 pc=0x4a2f31 entry=0x4a2f00 (inlined)", DefaultStackFrameFormatter.Copy().SetOptions(opts).Format(f))
+
+	f.PC, f.Entry, f.Inlined = 0, 0, false
+	assert.Equal(t, "main.run@/src/main.go:7", DefaultStackFrameFormatter.Copy().SetOptions(opts).Format(f))
+}
+
+// withoutAddresses clears the addresses of the frames, which aren't restored by UnmarshalJSON
+func withoutAddresses(s StackTrace) StackTrace {
+	s.Frames = append([]Frame(nil), s.Frames...)
+	for i := range s.Frames {
+		s.Frames[i].PC, s.Frames[i].Entry = 0, 0
+	}
+
+	return s
+}
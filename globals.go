@@ -1,11 +1,20 @@
 package errstack
 
+// DefaultMaxStackDepth is the maximum number of frames recorded by an error which wasn't created with
+// the WithMaxDepth option
+var DefaultMaxStackDepth = _MAX_CALL_DEPTH
+
 var DefaultStackErrorFormatter ErrorFormatter = &errorFormatter{
 	stFmt: DefaultStackTraceFormatter,
-	opts:  ErrorFormatterOptions{
+	opts: ErrorFormatterOptions{
 		// ErrorSeparator:      "",
 		// StackTraceSeparator: "",
 		// ErrorPrefix: "Error: ",
+		FieldsPrefix:   " {",
+		FieldsSuffix:   "}",
+		FieldSeparator: ", ",
+		CodePrefix:     "[",
+		CodeSuffix:     "] ",
 	},
 }
 
@@ -15,6 +24,24 @@ var DefaultChainErrorFormatter ErrorFormatter = &chainErrorFormatter{
 		ErrorSeparator: ", ",
 		// StackTraceSeparator: "",
 		// ErrorPrefix: "Error: ",
+		FieldsPrefix:   " {",
+		FieldsSuffix:   "}",
+		FieldSeparator: ", ",
+		CodePrefix:     "[",
+		CodeSuffix:     "] ",
+	},
+}
+
+var DefaultMultiErrorFormatter ErrorFormatter = &multiErrorFormatter{
+	sfmt: DefaultStackTraceFormatter,
+	opts: ErrorFormatterOptions{
+		ErrorSeparator:  ", ",
+		MemberSeparator: "; ",
+		FieldsPrefix:    " {",
+		FieldsSuffix:    "}",
+		FieldSeparator:  ", ",
+		CodePrefix:      "[",
+		CodeSuffix:      "] ",
 	},
 }
 
@@ -23,6 +50,11 @@ var DefaultStackFrameFormatter FrameFormatter = &frameFormatter{
 		LocationPrefix: "@",
 		// LocationSuffix:    "]",
 		FileLineSeparator: ":",
+		PCPrefix:          " pc=0x",
+		EntryPrefix:       " entry=0x",
+		InlinedMarker:     " (inlined)",
+		MessagePrefix:     " [",
+		MessageSuffix:     "]",
 	},
 }
 
@@ -30,8 +62,12 @@ var DefaultStackTraceFormatter StackTraceFormatter = &stackTraceFormatter{
 	ffmt: DefaultStackFrameFormatter,
 	opts: StackTraceFormatOptions{
 		// FrameIndent: "\t",
-		FrameSeparator: ";",
-		IndexPrefix:    "#",
-		IndexSuffix:    ": ",
+		FrameSeparator:   ";",
+		IndexPrefix:      "#",
+		IndexSuffix:      ": ",
+		TruncationMarker: "...",
+		SampledMarker:    "trace omitted (sampled)",
+		ElisionPrefix:    "... ",
+		ElisionSuffix:    " frames elided",
 	},
 }
@@ -0,0 +1,105 @@
+package errstack
+
+import (
+	"context"
+	"sync"
+)
+
+const spawnErrString = "spawned from here"
+
+// Group runs tasks in goroutines, like golang.org/x/sync/errgroup, and returns the first error. The
+// error of a failing task is chained to an error carrying the stack trace of the Go call which spawned
+// the task, so that the trace shows both where the task failed and who launched it. The zero value is
+// a valid Group with no limit, which doesn't cancel anything on error.
+type Group struct {
+	cancel  context.CancelCauseFunc
+	wg      sync.WaitGroup
+	sem     chan struct{}
+	errOnce sync.Once
+	err     error
+}
+
+// GroupWithContext returns a Group and a context derived from ctx, which is canceled when a task fails or
+// when Wait returns, whichever happens first
+func GroupWithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of tasks running at once. A negative limit removes it. The limit must not be
+// changed while tasks are running.
+func (self *Group) SetLimit(n int) {
+	if n < 0 {
+		self.sem = nil
+		return
+	}
+
+	self.sem = make(chan struct{}, n)
+}
+
+// Go runs f in a new goroutine, blocking until the limit of the group allows it
+//
+//go:noinline
+func (self *Group) Go(f func() error) {
+	if self.sem != nil {
+		self.sem <- struct{}{}
+	}
+
+	self.spawn(f, callersPCs(2, max(1, DefaultMaxStackDepth)+1))
+}
+
+// TryGo runs f in a new goroutine if the limit of the group allows it, and reports whether it did
+//
+//go:noinline
+func (self *Group) TryGo(f func() error) bool {
+	if self.sem != nil {
+		select {
+		case self.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+
+	self.spawn(f, callersPCs(2, max(1, DefaultMaxStackDepth)+1))
+
+	return true
+}
+
+// Wait blocks until all the tasks return, and returns the first error
+func (self *Group) Wait() error {
+	self.wg.Wait()
+
+	if self.cancel != nil {
+		self.cancel(self.err)
+	}
+
+	return self.err
+}
+
+func (self *Group) spawn(f func() error, pcs []uintptr) {
+	self.wg.Add(1)
+
+	go func() {
+		defer self.done()
+
+		err := f()
+		if err == nil {
+			return
+		}
+
+		self.errOnce.Do(func() {
+			self.err = newJoinedChain([]error{err, newStacktraceErrorPCs(nil, spawnErrString, pcs)})
+			if self.cancel != nil {
+				self.cancel(self.err)
+			}
+		})
+	}()
+}
+
+func (self *Group) done() {
+	if self.sem != nil {
+		<-self.sem
+	}
+
+	self.wg.Done()
+}
@@ -0,0 +1,84 @@
+package errstack
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Group(t *testing.T) {
+	t.Run("spawn site", func(t *testing.T) {
+		errTask := NewSentinel("task failed", WithStack())
+
+		g := &Group{}
+		g.Go(func() error { return nil })
+		g.Go(func() error { return errTask.Throw() })
+
+		err := g.Wait()
+		assert.ErrorIs(t, err, errTask)
+
+		chErr, ok := err.(ChainedError)
+		if !assert.True(t, ok) || !assert.NotNil(t, chErr.Next()) {
+			t.FailNow()
+		}
+
+		assert.Equal(t, "task failed, "+spawnErrString, err.Error())
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Group.func1.2", chErr.Inner().StackTrace().Frames[0].Function)
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Group.func1", chErr.Next().Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("chained task error", func(t *testing.T) {
+		taskErr := NewChainString("outer").Chain(NewString("inner"))
+
+		g := &Group{}
+		g.Go(func() error { return taskErr })
+
+		assert.Equal(t, "outer, inner, "+spawnErrString, g.Wait().Error())
+		assert.Equal(t, "outer, inner", taskErr.Error())
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		g, ctx := GroupWithContext(context.Background())
+
+		g.Go(func() error { return errors.New("boom") })
+		g.Go(func() error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		err := g.Wait()
+		assert.Equal(t, "boom, "+spawnErrString, err.Error())
+		assert.Equal(t, err, context.Cause(ctx))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		g := &Group{}
+		g.SetLimit(2)
+
+		running, peak := atomic.Int32{}, atomic.Int32{}
+		for i := 0; i < 8; i++ {
+			g.Go(func() error {
+				n := running.Add(1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+		}
+
+		assert.NoError(t, g.Wait())
+		assert.LessOrEqual(t, peak.Load(), int32(2))
+
+		block := make(chan struct{})
+		g.SetLimit(1)
+		assert.True(t, g.TryGo(func() error { <-block; return nil }))
+		assert.False(t, g.TryGo(func() error { return nil }))
+		close(block)
+		assert.NoError(t, g.Wait())
+	})
+}
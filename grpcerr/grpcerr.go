@@ -0,0 +1,196 @@
+// Package grpcerr converts errstack errors to gRPC statuses and back. The stack trace of every element of
+// an error chain travels as a google.rpc.DebugInfo detail, so that the chain can be rebuilt by the client.
+package grpcerr
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nnishant776/errstack"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+type Options struct {
+	// Debug adds the stack traces to the DebugInfo details. Without it, the details only hold the messages of
+	// the elements, which still lets the client rebuild the chain.
+	Debug bool
+}
+
+// DefaultOptions are the options used by ToStatus and by the server interceptors. Stack traces may reveal
+// the internals of the server, so Debug is disabled by default and should only be enabled for trusted clients.
+var DefaultOptions = Options{}
+
+// GRPCCode maps an error code to a gRPC status code
+func GRPCCode(code errstack.Code) codes.Code {
+	if code == errstack.CodeNone || code > errstack.CodeUnauthenticated {
+		return codes.Unknown
+	}
+
+	return codes.Code(code)
+}
+
+// Code maps a gRPC status code to an error code
+func Code(code codes.Code) errstack.Code {
+	if code == codes.OK || code > codes.Unauthenticated {
+		return errstack.CodeNone
+	}
+
+	return errstack.Code(code)
+}
+
+// ToStatus converts err to a gRPC status with DefaultOptions
+func ToStatus(err error) *status.Status {
+	return ToStatusWithOptions(err, DefaultOptions)
+}
+
+// ToStatusWithOptions converts err to a gRPC status. Errors which already carry a gRPC status and aren't
+// errstack errors are returned as is.
+func ToStatusWithOptions(err error, opts Options) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	if !isErrstack(err) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+	}
+
+	st := status.New(GRPCCode(errstack.CodeOf(err)), err.Error())
+
+	details := debugInfos(err, opts)
+	if len(details) <= 0 {
+		return st
+	}
+
+	if withDetails, detailErr := st.WithDetails(details...); detailErr == nil {
+		st = withDetails
+	}
+
+	return st
+}
+
+// FromStatus rebuilds the error sent with ToStatus. Every DebugInfo detail becomes an element of the chain.
+// The returned error still reports the status through status.FromError.
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	opts := []errstack.StackErrOption{
+		errstack.WithCode(Code(st.Code())),
+		errstack.WithSentinel(st.Err()),
+	}
+
+	elems := ([]error)(nil)
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.DebugInfo)
+		if !ok {
+			continue
+		}
+
+		elemOpts := []errstack.StackErrOption(nil)
+		if len(elems) == 0 {
+			elemOpts = opts
+		}
+
+		elems = append(elems, errstack.NewFromStackTrace(info.GetDetail(), parseStackEntries(info.GetStackEntries()), elemOpts...))
+	}
+
+	switch len(elems) {
+	case 0:
+		return errstack.NewString(st.Message(), opts...)
+	case 1:
+		return elems[0]
+	}
+
+	chErr := errstack.NewChain(elems[len(elems)-1])
+	for i := len(elems) - 2; i >= 0; i-- {
+		chErr = errstack.NewChain(elems[i]).Chain(chErr).(*errstack.ChainedStacktraceError)
+	}
+
+	return chErr
+}
+
+// FromError converts an error returned by a gRPC client call with FromStatus. Errors which don't carry a
+// gRPC status are returned as is.
+func FromError(err error) error {
+	if err == nil || isErrstack(err) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	return FromStatus(st)
+}
+
+func isErrstack(err error) bool {
+	switch err.(type) {
+	case errstack.Error, errstack.ChainedError:
+		return true
+	}
+
+	return false
+}
+
+func debugInfos(err error, opts Options) []protoadapt.MessageV1 {
+	chErr, ok := err.(errstack.ChainedError)
+	if !ok {
+		if stErr, ok := err.(errstack.StackTracer); ok {
+			return []protoadapt.MessageV1{newDebugInfo(err.Error(), stErr.StackTrace(), opts)}
+		}
+		return nil
+	}
+
+	details := ([]protoadapt.MessageV1)(nil)
+	for elem := chErr; elem != nil; elem = elem.Next() {
+		details = append(details, newDebugInfo(elem.Inner().Error(), elem.Inner().StackTrace(), opts))
+	}
+
+	return details
+}
+
+func newDebugInfo(detail string, stackTrace errstack.StackTrace, opts Options) *errdetails.DebugInfo {
+	info := &errdetails.DebugInfo{
+		Detail: detail,
+	}
+
+	if !opts.Debug {
+		return info
+	}
+
+	info.StackEntries = make([]string, 0, len(stackTrace.Frames))
+
+	for _, f := range stackTrace.Frames {
+		info.StackEntries = append(info.StackEntries, f.Function+"@"+f.File+":"+strconv.Itoa(f.Line))
+	}
+
+	return info
+}
+
+func parseStackEntries(entries []string) errstack.StackTrace {
+	stackTrace := errstack.StackTrace{
+		Frames: make([]errstack.Frame, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		f := errstack.Frame{}
+		f.Function, f.File, _ = strings.Cut(entry, "@")
+
+		if i := strings.LastIndexByte(f.File, ':'); i >= 0 {
+			if line, err := strconv.Atoi(f.File[i+1:]); err == nil {
+				f.File, f.Line = f.File[:i], line
+			}
+		}
+
+		stackTrace.Frames = append(stackTrace.Frames, f)
+	}
+
+	return stackTrace
+}
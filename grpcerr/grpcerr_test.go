@@ -0,0 +1,165 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/nnishant776/errstack"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func Test_StatusRoundTrip(t *testing.T) {
+	t.Run("stacktrace error", func(t *testing.T) {
+		err := errstack.NewString("user not found", errstack.WithCode(errstack.CodeNotFound), errstack.WithStack())
+
+		st := ToStatusWithOptions(err, Options{Debug: true})
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "user not found", st.Message())
+
+		decoded := FromStatus(st)
+		stErr, ok := decoded.(*errstack.StacktraceError)
+		if !assert.True(t, ok) {
+			t.FailNow()
+		}
+
+		assert.Equal(t, "user not found", stErr.Error())
+		assert.Equal(t, errstack.CodeNotFound, errstack.CodeOf(decoded))
+		assert.Equal(t, err.StackTrace().String(), stErr.StackTrace().String())
+		assert.Equal(t, codes.NotFound, status.Code(decoded))
+	})
+
+	t.Run("chained error", func(t *testing.T) {
+		err := errstack.Chain(
+			errstack.NewString("loading profile", errstack.WithStack()),
+			errstack.NewString("user not found", errstack.WithCode(errstack.CodeNotFound), errstack.WithStack()),
+		)
+
+		decoded := FromStatus(ToStatusWithOptions(err, Options{Debug: true}))
+		chErr, ok := decoded.(errstack.ChainedError)
+		if !assert.True(t, ok) {
+			t.FailNow()
+		}
+
+		assert.Equal(t, err.Error(), chErr.Error())
+		assert.Equal(t, err.Next().Inner().StackTrace().String(), chErr.Next().Inner().StackTrace().String())
+		assert.Equal(t, errstack.CodeNotFound, errstack.CodeOf(decoded))
+		assert.Equal(t, codes.NotFound, status.Code(decoded))
+	})
+
+	t.Run("stack traces only in debug mode", func(t *testing.T) {
+		err := errstack.Chain(
+			errstack.NewString("loading profile", errstack.WithStack()),
+			errstack.NewString("user not found", errstack.WithStack()),
+		)
+
+		st := ToStatus(err)
+		for _, detail := range st.Details() {
+			assert.Empty(t, detail.(*errdetails.DebugInfo).GetStackEntries())
+		}
+
+		decoded := FromStatus(st)
+		assert.Equal(t, err.Error(), decoded.Error())
+		assert.Empty(t, decoded.(errstack.ChainedError).Inner().StackTrace().Frames)
+	})
+
+	t.Run("plain errors", func(t *testing.T) {
+		assert.Equal(t, codes.Unknown, ToStatus(errors.New("boom")).Code())
+		assert.Equal(t, codes.Aborted, ToStatus(status.Error(codes.Aborted, "retry")).Code())
+		assert.Nil(t, ToStatus(nil))
+		assert.Nil(t, FromStatus(status.New(codes.OK, "")))
+
+		decoded := FromStatus(status.New(codes.Unavailable, "down"))
+		assert.Equal(t, "down", decoded.Error())
+		assert.Equal(t, errstack.CodeUnavailable, errstack.CodeOf(decoded))
+	})
+
+	t.Run("stack entry parsing", func(t *testing.T) {
+		stackTrace := parseStackEntries([]string{"main.main@/src/main.go:12", "main.run@C:/src/main.go:7", "runtime.goexit@?"})
+		assert.Equal(t, []errstack.Frame{
+			{Function: "main.main", File: "/src/main.go", Line: 12},
+			{Function: "main.run", File: "C:/src/main.go", Line: 7},
+			{Function: "runtime.goexit", File: "?"},
+		}, stackTrace.Frames)
+	})
+}
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	err error
+}
+
+func (self *healthServer) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return nil, self.err
+}
+
+func (self *healthServer) Watch(*grpc_health_v1.HealthCheckRequest, grpc_health_v1.Health_WatchServer) error {
+	return self.err
+}
+
+func Test_Interceptors(t *testing.T) {
+	defaultOptions := DefaultOptions
+	DefaultOptions.Debug = true
+	defer func() { DefaultOptions = defaultOptions }()
+
+	srvErr := errstack.Chain(
+		errstack.NewString("checking health", errstack.WithStack()),
+		errstack.NewString("database unavailable", errstack.WithCode(errstack.CodeUnavailable), errstack.WithStack()),
+	)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor()),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, &healthServer{err: srvErr})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	check := func(t *testing.T, err error) {
+		chErr, ok := err.(errstack.ChainedError)
+		if !assert.True(t, ok, "%T", err) {
+			return
+		}
+
+		assert.Equal(t, srvErr.Error(), chErr.Error())
+		assert.Equal(t, srvErr.Inner().StackTrace().String(), chErr.Inner().StackTrace().String())
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		check(t, err)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		_, err = stream.Recv()
+		check(t, err)
+	})
+}
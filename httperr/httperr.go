@@ -0,0 +1,200 @@
+// Package httperr renders errstack errors as RFC 7807 problem details.
+package httperr
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/nnishant776/errstack"
+)
+
+const (
+	ContentType string = "application/problem+json"
+)
+
+type Options struct {
+	// Debug adds the stack traces and the fields of the error to the problem details. It must not be
+	// enabled for responses sent to untrusted clients.
+	Debug bool
+	// Type is the URI reference identifying the problem type. It defaults to "about:blank".
+	Type string
+}
+
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Code     errstack.Code  `json:"code,omitempty"`
+	Trace    []TraceEntry   `json:"trace,omitempty"`
+	Fields   map[string]any `json:"fields,omitempty"`
+}
+
+type TraceEntry struct {
+	Error string           `json:"error"`
+	Stack []errstack.Frame `json:"stack,omitempty"`
+}
+
+// StatusCode maps an error code to the HTTP status code used for the response
+func StatusCode(code errstack.Code) int {
+	switch code {
+	case errstack.CodeInvalidArgument, errstack.CodeFailedPrecondition, errstack.CodeOutOfRange:
+		return http.StatusBadRequest
+	case errstack.CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case errstack.CodePermissionDenied:
+		return http.StatusForbidden
+	case errstack.CodeNotFound:
+		return http.StatusNotFound
+	case errstack.CodeAlreadyExists, errstack.CodeAborted:
+		return http.StatusConflict
+	case errstack.CodeResourceExhausted:
+		return http.StatusTooManyRequests
+	case errstack.CodeCanceled:
+		return 499
+	case errstack.CodeUnimplemented:
+		return http.StatusNotImplemented
+	case errstack.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case errstack.CodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func NewProblem(err error, opts Options) Problem {
+	code := errstack.CodeOf(err)
+
+	p := Problem{
+		Type:   opts.Type,
+		Status: StatusCode(code),
+		Code:   code,
+	}
+
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+
+	if err == nil {
+		p.Title = http.StatusText(p.Status)
+		return p
+	}
+
+	p.Title, p.Detail = err.Error(), err.Error()
+
+	if chErr, ok := err.(errstack.ChainedError); ok {
+		p.Title = chErr.Inner().Error()
+	}
+
+	if opts.Debug {
+		p.Trace = traceEntries(err)
+
+		if f, ok := err.(errstack.Fielder); ok {
+			p.Fields = f.Fields()
+		}
+	}
+
+	return p
+}
+
+func traceEntries(err error) []TraceEntry {
+	chErr, ok := err.(errstack.ChainedError)
+	if !ok {
+		entry := TraceEntry{Error: err.Error()}
+		if stErr, ok := err.(errstack.StackTracer); ok {
+			entry.Stack = stErr.StackTrace().Frames
+		}
+		return []TraceEntry{entry}
+	}
+
+	entries := ([]TraceEntry)(nil)
+	for elem := chErr; elem != nil; elem = elem.Next() {
+		entries = append(entries, TraceEntry{
+			Error: elem.Inner().Error(),
+			Stack: elem.Inner().StackTrace().Frames,
+		})
+	}
+
+	return entries
+}
+
+// Write renders err as problem details with the status code mapped from the code of the error
+func Write(w http.ResponseWriter, r *http.Request, err error, opts Options) {
+	p := NewProblem(err, opts)
+	if r != nil && r.URL != nil {
+		p.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Middleware recovers the panics raised by the handlers it wraps, and renders them as problem details
+// with the stack trace of the panic site. http.ErrAbortHandler is raised again, as net/http expects. When
+// the handler had already started the response, which can't be replaced anymore, the recovered error is
+// raised again instead, so that net/http logs it along with the stack trace of the panic site.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseWriter{ResponseWriter: w}
+
+			err := errstack.Catch(func() error {
+				next.ServeHTTP(rw, r)
+				return nil
+			})
+
+			if err == nil {
+				return
+			}
+
+			if errors.Is(err, http.ErrAbortHandler) {
+				panic(http.ErrAbortHandler)
+			}
+
+			if rw.written {
+				panic(err)
+			}
+
+			Write(w, r, err, opts)
+		})
+	}
+}
+
+// responseWriter records whether the handler started the response
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (self *responseWriter) WriteHeader(statusCode int) {
+	self.written = true
+	self.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (self *responseWriter) Write(b []byte) (int, error) {
+	self.written = true
+	return self.ResponseWriter.Write(b)
+}
+
+func (self *responseWriter) Flush() {
+	self.written = true
+	http.NewResponseController(self.ResponseWriter).Flush()
+}
+
+// Hijack hands the connection over to the handler, which owns the response from then on
+func (self *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	self.written = true
+	return http.NewResponseController(self.ResponseWriter).Hijack()
+}
+
+// Unwrap gives http.ResponseController access to the wrapped writer
+func (self *responseWriter) Unwrap() http.ResponseWriter {
+	return self.ResponseWriter
+}
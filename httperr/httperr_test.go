@@ -0,0 +1,164 @@
+package httperr
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nnishant776/errstack"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Write(t *testing.T) {
+	errNotFound := errstack.NewSentinel("user not found", errstack.WithCode(errstack.CodeNotFound))
+
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) Problem {
+		p := Problem{}
+		if !assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p)) {
+			t.FailNow()
+		}
+		return p
+	}
+
+	t.Run("chained error", func(t *testing.T) {
+		err := errstack.Chain(errstack.NewString("loading profile"), errNotFound.Throw())
+
+		rec := httptest.NewRecorder()
+		Write(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil), err, Options{})
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+
+		p := decode(t, rec)
+		assert.Equal(t, Problem{
+			Type:     "about:blank",
+			Title:    "loading profile",
+			Status:   http.StatusNotFound,
+			Detail:   "loading profile, user not found",
+			Instance: "/users/42",
+			Code:     errstack.CodeNotFound,
+		}, p)
+	})
+
+	t.Run("debug trace", func(t *testing.T) {
+		err := errstack.NewString("invalid id", errstack.WithCode(errstack.CodeInvalidArgument), errstack.WithStack())
+
+		rec := httptest.NewRecorder()
+		Write(rec, httptest.NewRequest(http.MethodGet, "/users/x", nil), err, Options{Debug: true})
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		p := decode(t, rec)
+		if assert.Len(t, p.Trace, 1) {
+			assert.Equal(t, "invalid id", p.Trace[0].Error)
+			assert.Equal(t, "github.com/nnishant776/errstack/httperr.Test_Write.func3", p.Trace[0].Stack[0].Function)
+		}
+	})
+
+	t.Run("fields only in debug mode", func(t *testing.T) {
+		err := errstack.NewString("query failed", errstack.WithFields(map[string]any{"sql": "SELECT * FROM users"}))
+
+		assert.Nil(t, NewProblem(err, Options{}).Fields)
+		assert.Equal(t, map[string]any{"sql": "SELECT * FROM users"}, NewProblem(err, Options{Debug: true}).Fields)
+
+		rec := httptest.NewRecorder()
+		Write(rec, httptest.NewRequest(http.MethodGet, "/users", nil), err, Options{})
+		assert.NotContains(t, rec.Body.String(), "SELECT")
+	})
+
+	t.Run("status mapping", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, StatusCode(errstack.CodeNone))
+		assert.Equal(t, http.StatusServiceUnavailable, StatusCode(errstack.CodeUnavailable))
+		assert.Equal(t, http.StatusUnauthorized, StatusCode(errstack.CodeUnauthenticated))
+		assert.Equal(t, http.StatusConflict, StatusCode(errstack.CodeAlreadyExists))
+	})
+}
+
+//go:noinline
+func panicInHandler(w http.ResponseWriter, r *http.Request) {
+	panic("handler exploded")
+}
+
+func Test_Middleware(t *testing.T) {
+	handler := Middleware(Options{Debug: true})(http.HandlerFunc(panicInHandler))
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/boom")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	p := Problem{}
+	if assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p)) {
+		assert.Equal(t, "panic: handler exploded", p.Detail)
+		assert.Equal(t, "/boom", p.Instance)
+		if assert.NotEmpty(t, p.Trace) {
+			assert.Equal(t, "github.com/nnishant776/errstack/httperr.panicInHandler", p.Trace[0].Stack[0].Function)
+		}
+	}
+
+	t.Run("response already started", func(t *testing.T) {
+		handler := Middleware(Options{Debug: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("partial"))
+			panic("late failure")
+		}))
+
+		rec := httptest.NewRecorder()
+		assert.PanicsWithError(t, "panic: late failure", func() {
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "partial", rec.Body.String())
+	})
+
+	t.Run("hijacked connection", func(t *testing.T) {
+		handler := Middleware(Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hijacker, ok := w.(http.Hijacker)
+			if !assert.True(t, ok) {
+				return
+			}
+
+			conn, _, err := hijacker.Hijack()
+			if assert.NoError(t, err) {
+				conn.Close()
+			}
+			panic("after hijack")
+		}))
+
+		server, client := net.Pipe()
+		defer client.Close()
+
+		rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server}
+		assert.PanicsWithError(t, "panic: after hijack", func() {
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+		assert.True(t, rec.hijacked)
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		Middleware(Options{})(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	})
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn     net.Conn
+	hijacked bool
+}
+
+func (self *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	self.hijacked = true
+	return self.conn, bufio.NewReadWriter(bufio.NewReader(self.conn), bufio.NewWriter(self.conn)), nil
+}
@@ -4,6 +4,10 @@ type StackTracer interface {
 	StackTrace() StackTrace
 }
 
+type Fielder interface {
+	Fields() map[string]any
+}
+
 type Unwrapper interface {
 	Unwrap() error
 }
@@ -12,6 +16,12 @@ type Thrower interface {
 	Throw() Error
 }
 
+// MsgThrower is implemented by StacktraceError, which can be thrown with an annotation. It isn't part of
+// Error, so that the implementations of Error outside of this package keep satisfying it.
+type MsgThrower interface {
+	ThrowMsg(msg string) Error
+}
+
 type Chainer interface {
 	Chain(err error) ChainedError
 }
@@ -0,0 +1,230 @@
+package errstack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MultiError collects errors, e.g. the failures of operations run in parallel, keeping the stack trace of
+// every member. It is safe for concurrent use. errors.Is and errors.As look through all the members.
+type MultiError struct {
+	mu   sync.RWMutex
+	errs []error
+}
+
+func NewMultiError(errs ...error) *MultiError {
+	return (&MultiError{}).Append(errs...)
+}
+
+// Append adds the non nil errors to the members
+func (self *MultiError) Append(errs ...error) *MultiError {
+	if self == nil {
+		return nil
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	for _, err := range errs {
+		if err != nil {
+			self.errs = append(self.errs, err)
+		}
+	}
+
+	return self
+}
+
+func (self *MultiError) Len() int {
+	if self == nil {
+		return 0
+	}
+
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	return len(self.errs)
+}
+
+// Errors returns a copy of the members
+func (self *MultiError) Errors() []error {
+	if self == nil {
+		return nil
+	}
+
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	return append([]error(nil), self.errs...)
+}
+
+// ErrorOrNil returns nil if no error was collected, and the MultiError otherwise
+func (self *MultiError) ErrorOrNil() error {
+	if self.Len() == 0 {
+		return nil
+	}
+
+	return self
+}
+
+func (self *MultiError) Unwrap() []error {
+	return self.Errors()
+}
+
+func (self *MultiError) Error() string {
+	return fmt.Sprintf("%s", self)
+}
+
+func (self *MultiError) String() string {
+	if self == nil {
+		return NilErrorString
+	}
+
+	return DefaultMultiErrorFormatter.Format(self)
+}
+
+// MarshalJSON encodes the members as an array. Chained members are encoded as nested arrays and members
+// which aren't errstack errors as an object holding their message.
+func (self *MultiError) MarshalJSON() ([]byte, error) {
+	if self == nil {
+		return json.Marshal(nil)
+	}
+
+	errs := self.Errors()
+	members := make([]any, 0, len(errs))
+
+	for _, err := range errs {
+		switch err.(type) {
+		case json.Marshaler:
+			members = append(members, err)
+		default:
+			members = append(members, map[string]any{"error": err.Error()})
+		}
+	}
+
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON rebuilds the members from the output of MarshalJSON. Arrays become chains and objects become
+// stack trace errors.
+func (self *MultiError) UnmarshalJSON(data []byte) error {
+	if self == nil {
+		return errNilUnmarshalTarget
+	}
+
+	if string(data) == "null" {
+		return nil
+	}
+
+	members := ([]json.RawMessage)(nil)
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	errs := make([]error, 0, len(members))
+	for _, member := range members {
+		var err interface {
+			error
+			json.Unmarshaler
+		}
+
+		switch member = bytes.TrimSpace(member); {
+		case len(member) > 0 && member[0] == '[':
+			err = &ChainedStacktraceError{}
+		default:
+			err = &StacktraceError{}
+		}
+
+		if unmarshalErr := err.UnmarshalJSON(member); unmarshalErr != nil {
+			return unmarshalErr
+		}
+
+		errs = append(errs, err)
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.errs = errs
+
+	return nil
+}
+
+// Format formats the members according to the fmt.Formatter interface. The verbs and flags are the same as
+// for ChainedStacktraceError, and every member is printed with its stack trace. With the ' ', '+' and '#'
+// flags, every member starts on a new line and its lines are indented by the width, 2 by default.
+func (self *MultiError) Format(s fmt.State, verb rune) {
+	erFmt := DefaultMultiErrorFormatter
+	stFmt := erFmt.StackTraceFormatter()
+	ffFmt := stFmt.FrameFormatter()
+
+	eOpts := erFmt.Options()
+	fOpts := ffFmt.Options()
+	sOpts := stFmt.Options()
+
+	switch verb {
+	case 's':
+		if s.Flag('+') {
+			eOpts.ErrorSeparator = ": "
+			erFmt = erFmt.Copy().SetOptions(eOpts)
+		}
+		erFmt.FormatBuffer(s, self)
+
+	case 'v':
+		flags := byte(0)
+		switch {
+		case s.Flag(' '):
+			flags = flags | 1<<0
+		case s.Flag('-'):
+			flags = flags | 1<<1
+		case s.Flag('+'):
+			flags = flags | 1<<2
+		case s.Flag('#'):
+			flags = flags | 1<<3
+		default:
+		}
+
+		eOpts.StackTraceSeparator = "=>"
+		fOpts.SkipLocation = flags <= 1
+		sOpts.SkipStackIndex = flags&(1<<3) == 0
+
+		erFmt = erFmt.Copy()
+		stFmt = stFmt.Copy()
+		ffFmt = ffFmt.Copy()
+
+		if flags&0x0c > 0 {
+			eOpts.ShowFields = true
+		}
+
+		if flags&0x0d > 0 {
+			w, ok := s.Width()
+			if !ok {
+				w = 2
+			}
+
+			eOpts.ErrorSeparator = "\n"
+			eOpts.StackTraceSeparator = "\n"
+			eOpts.MemberSeparator = "\n"
+			eOpts.MemberIndent = strings.Repeat(" ", max(2, w))
+			sOpts.FrameSeparator = "\n"
+			if ok {
+				sOpts.FrameIndent = strings.Repeat(" ", max(2, w))
+			}
+		}
+
+		ffFmt.SetOptions(fOpts)
+		stFmt.SetOptions(sOpts).SetFrameFormatter(ffFmt)
+		erFmt.SetOptions(eOpts).SetStackTraceFormatter(stFmt)
+		erFmt.FormatBuffer(s, self)
+
+	case 'j':
+		enc := json.NewEncoder(s)
+		if s.Flag('+') {
+			w, _ := s.Width()
+			enc.SetIndent("", strings.Repeat(" ", max(2, w)))
+		}
+		enc.Encode(self)
+	}
+}
@@ -0,0 +1,116 @@
+package errstack
+
+import (
+	"io"
+	"strings"
+)
+
+var _ ErrorFormatter = (*multiErrorFormatter)(nil)
+
+// multiErrorFormatter formats every member of a MultiError like the chain error formatter does. Members are
+// separated by MemberSeparator and every line of a member is indented with MemberIndent.
+type multiErrorFormatter struct {
+	opts ErrorFormatterOptions
+	sfmt StackTraceFormatter
+}
+
+func (self *multiErrorFormatter) format(w io.Writer, err error) {
+	if err == nil {
+		w.Write(string2Slice(NilErrorString))
+		return
+	}
+
+	errs := []error{err}
+	if mErr, ok := err.(*MultiError); ok {
+		if mErr == nil {
+			w.Write(string2Slice(NilErrorString))
+			return
+		}
+		errs = mErr.Errors()
+	}
+
+	for i, e := range errs {
+		if i > 0 {
+			switch o := w.(type) {
+			case io.StringWriter:
+				o.WriteString(self.opts.MemberSeparator)
+			default:
+				w.Write(string2Slice(self.opts.MemberSeparator))
+			}
+		}
+
+		if self.opts.MemberIndent == "" {
+			self.formatMember(w, e)
+			continue
+		}
+
+		sb := strings.Builder{}
+		self.formatMember(&sb, e)
+		writeIndented(w, sb.String(), self.opts.MemberIndent)
+	}
+}
+
+func (self *multiErrorFormatter) formatMember(w io.Writer, err error) {
+	if _, ok := err.(*MultiError); ok {
+		self.format(w, err)
+		return
+	}
+
+	(&chainErrorFormatter{opts: self.opts, sfmt: self.sfmt}).format(w, err)
+}
+
+func (self *multiErrorFormatter) Options() ErrorFormatterOptions {
+	return self.opts
+}
+
+func (self *multiErrorFormatter) StackTraceFormatter() StackTraceFormatter {
+	return self.sfmt
+}
+
+func (self *multiErrorFormatter) Format(e error) string {
+	sb := strings.Builder{}
+	self.format(&sb, e)
+	return sb.String()
+}
+
+func (self *multiErrorFormatter) FormatBuffer(w io.Writer, e error) {
+	self.format(w, e)
+}
+
+func (self *multiErrorFormatter) Clone() ErrorFormatter {
+	return &multiErrorFormatter{
+		opts: self.opts,
+		sfmt: self.sfmt.Clone(),
+	}
+}
+
+func (self *multiErrorFormatter) Copy() ErrorFormatter {
+	return &multiErrorFormatter{
+		opts: self.opts,
+		sfmt: self.sfmt,
+	}
+}
+
+func (self *multiErrorFormatter) SetOptions(opts ErrorFormatterOptions) ErrorFormatter {
+	self.opts = opts
+	return self
+}
+
+func (self *multiErrorFormatter) SetStackTraceFormatter(stFmt StackTraceFormatter) ErrorFormatter {
+	self.sfmt = stFmt
+	return self
+}
+
+func writeIndented(w io.Writer, s string, indent string) {
+	for len(s) > 0 {
+		line, rest, found := strings.Cut(s, "\n")
+
+		w.Write(string2Slice(indent))
+		w.Write(string2Slice(line))
+		if found {
+			w.Write([]byte{'\n'})
+		}
+
+		s = rest
+	}
+}
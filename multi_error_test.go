@@ -0,0 +1,110 @@
+package errstack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MultiError(t *testing.T) {
+	errNotFound := NewSentinel("not found")
+
+	t.Run("concurrent append", func(t *testing.T) {
+		mErr := NewMultiError()
+		assert.Nil(t, mErr.ErrorOrNil())
+
+		done := make(chan struct{})
+		for i := 0; i < 8; i++ {
+			go func(i int) {
+				mErr.Append(NewString(fmt.Sprintf("task %d", i)), nil)
+				done <- struct{}{}
+			}(i)
+		}
+		for i := 0; i < 8; i++ {
+			<-done
+		}
+
+		assert.Equal(t, 8, mErr.Len())
+		assert.Len(t, mErr.Unwrap(), 8)
+		assert.Equal(t, mErr, mErr.ErrorOrNil())
+	})
+
+	t.Run("is and as", func(t *testing.T) {
+		mErr := NewMultiError(errors.New("plain"), NewChain(errNotFound.Throw()))
+
+		assert.ErrorIs(t, mErr, errNotFound)
+
+		chErr := (ChainedError)(nil)
+		assert.True(t, errors.As(mErr, &chErr))
+	})
+
+	t.Run("formatting", func(t *testing.T) {
+		mErr := NewMultiError(
+			NewString("first", WithStack()),
+			NewChainString("outer").Chain(NewString("inner")),
+			errors.New("plain"),
+		)
+
+		assert.Equal(t, "first; outer, inner; plain", mErr.Error())
+		assert.Equal(t, "first; outer: inner; plain", fmt.Sprintf("%+s", mErr))
+
+		lines := strings.Split(fmt.Sprintf("%+v", mErr), "\n")
+		assert.Equal(t, "  first", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "  github.com/nnishant776/errstack.Test_MultiError.func3@"), lines[1])
+		assert.Contains(t, lines, "  outer")
+		assert.Contains(t, lines, "  inner")
+		assert.Equal(t, "  plain", lines[len(lines)-1])
+
+		nested := NewMultiError(NewString("a"), NewMultiError(NewString("b")))
+		assert.Equal(t, "  a\n    b", fmt.Sprintf("% v", nested))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		mErr := NewMultiError(
+			NewString("first", WithStack()),
+			NewChainString("outer").Chain(NewString("inner")),
+			errors.New("plain"),
+		)
+
+		b, err := json.Marshal(mErr)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.True(t, strings.HasPrefix(string(b), "["))
+
+		decoded := &MultiError{}
+		if !assert.NoError(t, json.Unmarshal(b, decoded)) {
+			t.FailNow()
+		}
+
+		assert.Equal(t, mErr.Error(), decoded.Error())
+		assert.Equal(t, withoutAddresses(mErr.Errors()[0].(Error).StackTrace()), decoded.Errors()[0].(Error).StackTrace())
+		assert.IsType(t, &ChainedStacktraceError{}, decoded.Errors()[1])
+	})
+}
+
+func Test_ChainJoinedErrors(t *testing.T) {
+	first := NewString("first", WithStack())
+	second := NewChainString("second").Chain(NewString("third", WithStack()))
+
+	for name, joined := range map[string]error{
+		"errors.Join": errors.Join(first, nil, second),
+		"MultiError":  NewMultiError(first, second),
+	} {
+		t.Run(name, func(t *testing.T) {
+			chErr := Chain(NewString("outer"), joined)
+			assert.Equal(t, "outer, first, second, third", chErr.Error())
+			assert.Equal(t, first.StackTrace(), chErr.Next().Inner().StackTrace())
+			assert.Equal(t, second.Next().Inner().StackTrace(), chErr.Next().Next().Next().Inner().StackTrace())
+
+			chErr = Chain(joined, NewString("last"))
+			assert.Equal(t, "first, second, third, last", chErr.Error())
+
+			assert.Equal(t, "second, third", second.Error())
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package errstack
+
+import (
+	"path"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+type PathTrimmer interface {
+	TrimPath(f Frame) string
+}
+
+var _ PathTrimmer = PathTrimmerFunc(nil)
+var _ PathTrimmer = PathPrefixMap(nil)
+var _ PathTrimmer = (*modulePathTrimmer)(nil)
+
+type PathTrimmerFunc func(f Frame) string
+
+func (self PathTrimmerFunc) TrimPath(f Frame) string {
+	return self(f)
+}
+
+// PathPrefixMap rewrites the longest matching prefix of a file path to its mapped value. Paths without a
+// matching prefix are kept as is.
+type PathPrefixMap map[string]string
+
+func (self PathPrefixMap) TrimPath(f Frame) string {
+	match := ""
+	for prefix := range self {
+		if len(prefix) > len(match) && strings.HasPrefix(f.File, prefix) {
+			match = prefix
+		}
+	}
+
+	if match == "" {
+		return f.File
+	}
+
+	return self[match] + f.File[len(match):]
+}
+
+type modulePathTrimmer struct {
+	goRoot  string
+	mainMod string
+	modules map[string]string
+}
+
+// NewModulePathTrimmer returns a PathTrimmer which rewrites the files of the standard library to the
+// "$GOROOT/src/..." form and the files of every other package to the module relative form, i.e.
+// "github.com/x/y@v1.2.3/file.go". Module versions are taken from the build information of the binary.
+func NewModulePathTrimmer() PathTrimmer {
+	trimmer := &modulePathTrimmer{
+		goRoot:  goRoot(),
+		modules: map[string]string{},
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		trimmer.mainMod = info.Main.Path
+		for _, dep := range info.Deps {
+			mod := dep
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				mod = dep.Replace
+			}
+			trimmer.modules[dep.Path] = dep.Path + "@" + mod.Version
+		}
+	}
+
+	return trimmer
+}
+
+// goRoot returns the GOROOT the binary was built with, found from the source file of a runtime function. It
+// is empty for binaries built with -trimpath.
+func goRoot() string {
+	fn := runtime.FuncForPC(reflect.ValueOf(runtime.GC).Pointer())
+	if fn == nil {
+		return ""
+	}
+
+	file, _ := fn.FileLine(fn.Entry())
+	if i := strings.LastIndex(file, "/src/runtime/"); i > 0 {
+		return path.Clean(file[:i])
+	}
+
+	return ""
+}
+
+func (self *modulePathTrimmer) TrimPath(f Frame) string {
+	if self.goRoot != "" && strings.HasPrefix(f.File, self.goRoot+"/src/") {
+		return "$GOROOT" + f.File[len(self.goRoot):]
+	}
+
+	if f.Function == "" || f.File == "" {
+		return f.File
+	}
+
+	pkg := f.Package()
+	if pkg == "main" && self.mainMod != "" {
+		pkg = self.mainMod
+	}
+
+	for mod := pkg; mod != "."; mod = path.Dir(mod) {
+		if modVer, ok := self.modules[mod]; ok {
+			return modVer + pkg[len(mod):] + "/" + path.Base(f.File)
+		}
+	}
+
+	return pkg + "/" + path.Base(f.File)
+}
@@ -0,0 +1,102 @@
+package errstack
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PathTrimmer(t *testing.T) {
+	t.Run("frame accessors", func(t *testing.T) {
+		f := Frame{Function: "github.com/acme/app/db.(*Conn).Query.func1"}
+		assert.Equal(t, "github.com/acme/app/db", f.Package())
+		assert.Equal(t, "(*Conn).Query.func1", f.ShortFunction())
+
+		f = Frame{Function: "runtime.goexit"}
+		assert.Equal(t, "runtime", f.Package())
+		assert.Equal(t, "goexit", f.ShortFunction())
+	})
+
+	t.Run("dotted package paths", func(t *testing.T) {
+		mods := dottedModules
+		dottedModules = func() []string { return append(mods(), "example.com/x.y") }
+		defer func() { dottedModules = mods }()
+
+		cases := []struct {
+			function string
+			pkg      string
+			short    string
+		}{
+			{"gopkg.in/yaml.v3.(*decoder).unmarshal", "gopkg.in/yaml.v3", "(*decoder).unmarshal"},
+			{"gopkg.in/check.v1.(*C).Assert.func1", "gopkg.in/check.v1", "(*C).Assert.func1"},
+			{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3", "Unmarshal"},
+			{"example.com/x%2ey.T.M", "example.com/x.y", "T.M"},
+			{"example.com/x%252ey.F.func1", "example.com/x.y", "F.func1"},
+			{"example.com/x.y.Func", "example.com/x.y", "Func"},
+			{"example.com/x.y/sub.Func", "example.com/x.y/sub", "Func"},
+			{"main.main", "main", "main"},
+		}
+
+		for _, c := range cases {
+			f := Frame{Function: c.function}
+			assert.Equal(t, c.pkg, f.Package(), c.function)
+			assert.Equal(t, c.short, f.ShortFunction(), c.function)
+		}
+	})
+
+	t.Run("module paths", func(t *testing.T) {
+		trimmer := NewModulePathTrimmer()
+
+		testifyVersion, yamlVersion := "", ""
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, dep := range info.Deps {
+				switch dep.Path {
+				case "github.com/stretchr/testify":
+					testifyVersion = dep.Version
+				case "gopkg.in/yaml.v3":
+					yamlVersion = dep.Version
+				}
+			}
+		}
+
+		assert.Equal(t, "$GOROOT/src/testing/testing.go", trimmer.TrimPath(Frame{
+			Function: "testing.tRunner",
+			File:     goRoot() + "/src/testing/testing.go",
+		}))
+		assert.Equal(t, "github.com/stretchr/testify@"+testifyVersion+"/assert/assertions.go", trimmer.TrimPath(Frame{
+			Function: "github.com/stretchr/testify/assert.Equal",
+			File:     "/home/ci/go/pkg/mod/github.com/stretchr/testify@" + testifyVersion + "/assert/assertions.go",
+		}))
+		if yamlVersion != "" {
+			assert.Equal(t, "gopkg.in/yaml.v3@"+yamlVersion+"/decode.go", trimmer.TrimPath(Frame{
+				Function: "gopkg.in/yaml.v3.(*decoder).unmarshal",
+				File:     "/home/ci/go/pkg/mod/gopkg.in/yaml.v3@" + yamlVersion + "/decode.go",
+			}))
+		}
+
+		err := NewString("Hello Errors!", WithStack(), WithPathTrimmer(trimmer))
+		frames := err.StackTrace().Frames
+		assert.Equal(t, "github.com/nnishant776/errstack/path_trimmer_test.go", frames[0].File)
+		assert.Equal(t, "$GOROOT/src/testing/testing.go", frames[1].File)
+	})
+
+	t.Run("prefix map", func(t *testing.T) {
+		trimmer := PathPrefixMap{"/home/ci/": "~/", "/home/ci/src/": ""}
+		assert.Equal(t, "app/main.go", trimmer.TrimPath(Frame{File: "/home/ci/src/app/main.go"}))
+		assert.Equal(t, "~/lib/util.go", trimmer.TrimPath(Frame{File: "/home/ci/lib/util.go"}))
+		assert.Equal(t, "/opt/app/main.go", trimmer.TrimPath(Frame{File: "/opt/app/main.go"}))
+	})
+
+	t.Run("frame formatter", func(t *testing.T) {
+		ffFmt := DefaultStackFrameFormatter.Clone()
+		fOpts := ffFmt.Options()
+		fOpts.PathTrimmer = PathPrefixMap{"/home/ci/src/": ""}
+		ffFmt.SetOptions(fOpts)
+
+		f := Frame{Function: "main.main", File: "/home/ci/src/app/main.go", Line: 12}
+		assert.Equal(t, "main.main@app/main.go:12", ffFmt.Format(f))
+		assert.True(t, strings.HasPrefix(f.String(), "main.main@/home/ci/src/"))
+	})
+}
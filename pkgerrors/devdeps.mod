@@ -0,0 +1,18 @@
+module github.com/nnishant776/errstack/pkgerrors
+
+go 1.21
+
+require (
+	github.com/nnishant776/errstack v0.1.0
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The tests run against the working tree, like in the go.work created by make workspace
+replace github.com/nnishant776/errstack => ../
@@ -0,0 +1,8 @@
+module github.com/nnishant776/errstack/pkgerrors
+
+go 1.21
+
+require (
+	github.com/nnishant776/errstack v0.1.0
+	github.com/pkg/errors v0.9.1
+)
@@ -0,0 +1,150 @@
+// Package pkgerrors exposes errstack errors through the interfaces of github.com/pkg/errors, so that
+// tooling reading pkg/errors stack traces, like error reporters and loggers, can read errstack errors too.
+// Importing it also registers a StackExtractor reading the stack traces of pkg/errors.
+package pkgerrors
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/nnishant776/errstack"
+	"github.com/pkg/errors"
+)
+
+var _ interface {
+	error
+	StackTrace() errors.StackTrace
+	Unwrap() error
+	fmt.Formatter
+} = (*Adapter)(nil)
+
+var _ interface{ Cause() error } = (*causeAdapter)(nil)
+
+func init() {
+	errstack.RegisterStackExtractor(errstack.StackExtractorFunc(extractStack))
+}
+
+// Adapter adapts an errstack error to the pkg/errors contract. The elements of a chain, or the error wrapped
+// by an errstack.Error, are exposed through Unwrap and, when there is one, through Cause.
+type Adapter struct {
+	err error
+}
+
+// causeAdapter is an Adapter with a cause. Cause must only be implemented by errors which have one, since
+// errors.Cause of pkg/errors stops at the first error without a Cause method.
+type causeAdapter struct {
+	*Adapter
+}
+
+// Adapt wraps the errstack errors, either errstack.Error or errstack.ChainedError, in an Adapter. Other
+// errors are returned as is.
+func Adapt(err error) error {
+	switch err.(type) {
+	case errstack.Error, errstack.ChainedError:
+	default:
+		return err
+	}
+
+	adapted := &Adapter{err: err}
+	if adapted.Unwrap() == nil {
+		return adapted
+	}
+
+	return &causeAdapter{Adapter: adapted}
+}
+
+func (self *Adapter) Error() string {
+	return self.err.Error()
+}
+
+// StackTrace converts the stack trace of the error, or of the first element of a chain. Frames which
+// weren't symbolized in this process, like the ones of decoded errors, are left out.
+func (self *Adapter) StackTrace() errors.StackTrace {
+	return StackTrace(self.err)
+}
+
+// Unwrap returns the next element of a chain, or the error wrapped by an errstack.Error
+func (self *Adapter) Unwrap() error {
+	switch e := self.err.(type) {
+	case errstack.ChainedError:
+		if e.Next() == nil {
+			return nil
+		}
+		return Adapt(e.Next())
+	case errstack.Error:
+		return Adapt(e.Unwrap())
+	}
+
+	return nil
+}
+
+// Is matches target against the adapted error, including all the elements of a chain
+func (self *Adapter) Is(target error) bool {
+	return stderrors.Is(self.err, target)
+}
+
+func (self *Adapter) As(target any) bool {
+	return stderrors.As(self.err, target)
+}
+
+func (self *causeAdapter) Cause() error {
+	return self.Unwrap()
+}
+
+// Format formats the underlying error, so that the errstack verbs and flags keep working
+func (self *Adapter) Format(s fmt.State, verb rune) {
+	if f, ok := self.err.(fmt.Formatter); ok {
+		f.Format(s, verb)
+		return
+	}
+
+	fmt.Fprintf(s, fmt.FormatString(s, verb), self.err)
+}
+
+// StackTrace converts the stack trace of an errstack error, or of the first element of a chain, to a
+// pkg/errors stack trace
+func StackTrace(err error) errors.StackTrace {
+	stackTrace := errstack.StackTrace{}
+
+	switch e := err.(type) {
+	case errstack.ChainedError:
+		if e.Inner() != nil {
+			stackTrace = e.Inner().StackTrace()
+		}
+	case errstack.StackTracer:
+		stackTrace = e.StackTrace()
+	}
+
+	frames := make(errors.StackTrace, 0, len(stackTrace.Frames))
+	for _, f := range stackTrace.Frames {
+		if f.PC == 0 {
+			continue
+		}
+
+		// pkg/errors frames hold return program counters, while Frame.PC points at the call
+		pc := errors.Frame(f.PC + 1)
+		if len(frames) > 0 && frames[len(frames)-1] == pc {
+			continue
+		}
+		frames = append(frames, pc)
+	}
+
+	return frames
+}
+
+// extractStack reads the frames of pkg/errors, which hold return program counters, just like the ones
+// captured by errstack
+func extractStack(err error) (errstack.ExtractedStack, bool) {
+	stErr, ok := err.(interface{ StackTrace() errors.StackTrace })
+	if !ok {
+		return errstack.ExtractedStack{}, false
+	}
+
+	stackTrace := stErr.StackTrace()
+	stack := errstack.ExtractedStack{PCs: make([]uintptr, 0, len(stackTrace))}
+	for _, f := range stackTrace {
+		stack.PCs = append(stack.PCs, uintptr(f))
+	}
+
+	return stack, true
+}
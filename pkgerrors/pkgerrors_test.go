@@ -0,0 +1,98 @@
+package pkgerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nnishant776/errstack"
+	pkgerrs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Adapt(t *testing.T) {
+	t.Run("stack trace", func(t *testing.T) {
+		err := errstack.NewString("boom", errstack.WithStack())
+
+		adapted := Adapt(err)
+		assert.IsType(t, &Adapter{}, adapted)
+
+		stErr, ok := adapted.(interface{ StackTrace() pkgerrs.StackTrace })
+		if !assert.True(t, ok) {
+			t.FailNow()
+		}
+
+		stackTrace := stErr.StackTrace()
+		if assert.NotEmpty(t, stackTrace) {
+			assert.Equal(t, "Test_Adapt.func1", fmt.Sprintf("%n", stackTrace[0]))
+			assert.Equal(t, fmt.Sprintf("%d", err.StackTrace().Frames[0].Line), fmt.Sprintf("%d", stackTrace[0]))
+		}
+
+		assert.Equal(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", adapted))
+		assert.Equal(t, "boom", adapted.Error())
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		inner := errstack.NewString("inner", errstack.WithStack())
+		chErr := errstack.NewChainString("outer").Chain(inner)
+
+		adapted := Adapt(chErr)
+		assert.Empty(t, StackTrace(adapted))
+
+		cause := pkgerrs.Cause(adapted)
+		assert.Equal(t, "inner", cause.Error())
+		assert.IsType(t, &Adapter{}, cause)
+		assert.Equal(t, StackTrace(inner), cause.(*Adapter).StackTrace())
+		assert.ErrorIs(t, adapted, inner)
+	})
+
+	t.Run("foreign errors", func(t *testing.T) {
+		err := errors.New("plain")
+		assert.Equal(t, err, Adapt(err))
+		assert.Nil(t, Adapt(nil))
+	})
+}
+
+func Test_StackExtractor(t *testing.T) {
+	origin := func() error {
+		return pkgerrs.New("pkg failure")
+	}
+
+	pkgErr := pkgerrs.Wrap(fmt.Errorf("context: %w", origin()), "wrapped")
+
+	t.Run("new", func(t *testing.T) {
+		stackTrace := errstack.New(pkgErr, errstack.WithStack()).StackTrace()
+		if assert.NotEmpty(t, stackTrace.Frames) {
+			assert.Equal(t, "github.com/nnishant776/errstack/pkgerrors.Test_StackExtractor.func1", stackTrace.Frames[0].Function)
+			assert.Equal(t, "github.com/nnishant776/errstack/pkgerrors.Test_StackExtractor", stackTrace.Frames[1].Function)
+		}
+
+		assert.Equal(t, stackTrace, errstack.New(pkgErr).StackTrace())
+	})
+
+	t.Run("throw", func(t *testing.T) {
+		err := errstack.New(pkgErr, errstack.WithStack())
+
+		thrown := func() errstack.Error {
+			return err.Throw()
+		}()
+
+		frames := thrown.StackTrace().Frames
+		if assert.Len(t, frames, len(err.StackTrace().Frames)+1) {
+			assert.Equal(t, err.StackTrace().Frames, frames[:len(frames)-1])
+			assert.Equal(t, "github.com/nnishant776/errstack/pkgerrors.Test_StackExtractor.func3.1", frames[len(frames)-1].Function)
+		}
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		chErr := errstack.Chain(errstack.NewString("outer"), pkgErr)
+		assert.Equal(t, "github.com/nnishant776/errstack/pkgerrors.Test_StackExtractor.func1", chErr.Next().Inner().StackTrace().Frames[0].Function)
+
+		chErr = errstack.NewChain(pkgErr)
+		assert.Equal(t, "github.com/nnishant776/errstack/pkgerrors.Test_StackExtractor.func1", chErr.Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("foreign errors without frames", func(t *testing.T) {
+		assert.Empty(t, errstack.New(fmt.Errorf("plain")).StackTrace().Frames)
+	})
+}
@@ -0,0 +1,59 @@
+package errstack
+
+import (
+	"fmt"
+)
+
+const (
+	_PANIC_RECOVERY_FRAMES int = 16
+)
+
+// Recover converts a panic into an error with the stack trace of the panic site and stores it in errp. It
+// must be deferred directly, as in `defer errstack.Recover(&err)`. If the panic value is already an Error,
+// its stack trace is kept and the panic site is chained to it.
+//
+//go:noinline
+func Recover(errp *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	err := newPanicError(v, 1)
+	if errp != nil {
+		*errp = err
+	}
+}
+
+// Catch runs fn and converts a panic raised by it into an error, in the same way as Recover
+func Catch(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+func newPanicError(v any, skip int) error {
+	buf := make([]uintptr, max(1, DefaultMaxStackDepth)+_PANIC_RECOVERY_FRAMES+1)
+	pcs := panicPCs(callersPCsBuf(skip+1, len(buf), buf))
+
+	switch e := v.(type) {
+	case ChainedError:
+		// The panic value may be shared, so the panic site is chained to a copy of it
+		return newJoinedChain([]error{e, newStacktraceErrorPCs(nil, "panic", pcs)})
+
+	case Error:
+		return NewChain(e).Chain(newStacktraceErrorPCs(nil, "panic", pcs))
+
+	case error:
+		return newStacktraceErrorPCs(e, "", pcs)
+
+	default:
+		return newStacktraceErrorPCs(nil, fmt.Sprintf("panic: %v", v), pcs)
+	}
+}
+
+func newStacktraceErrorPCs(err error, errStr string, pcs []uintptr) *StacktraceError {
+	stErr := newOrigin(err, errStr, stackErrOpts{})
+	stErr.adoptPCs(pcs)
+
+	return stErr
+}
@@ -0,0 +1,97 @@
+package errstack
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//go:noinline
+func recoverPanicSite(v any) {
+	panic(v)
+}
+
+func Test_Recover(t *testing.T) {
+	const panicSite = "github.com/nnishant776/errstack.recoverPanicSite"
+
+	t.Run("deferred recover", func(t *testing.T) {
+		run := func() (err error) {
+			defer Recover(&err)
+			recoverPanicSite("boom")
+			return nil
+		}
+
+		err := run()
+		stErr, ok := err.(*StacktraceError)
+		if !assert.True(t, ok, "expected *StacktraceError, got %T", err) {
+			return
+		}
+
+		assert.Equal(t, "panic: boom", stErr.Error())
+		assert.Equal(t, panicSite, stErr.StackTrace().Frames[0].Function)
+	})
+
+	t.Run("error panic value", func(t *testing.T) {
+		sentinel := errors.New("sentinel")
+		err := Catch(func() error {
+			recoverPanicSite(sentinel)
+			return nil
+		})
+
+		assert.True(t, errors.Is(err, sentinel))
+		assert.Equal(t, panicSite, err.(*StacktraceError).StackTrace().Frames[0].Function)
+	})
+
+	t.Run("runtime error", func(t *testing.T) {
+		err := Catch(func() error {
+			var m map[string]int
+			_ = []int{}[len(m)]
+			return nil
+		})
+
+		assert.Contains(t, err.Error(), "index out of range")
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Recover.func3.1", err.(*StacktraceError).StackTrace().Frames[0].Function)
+	})
+
+	t.Run("errstack panic value", func(t *testing.T) {
+		orig := NewString("Hello Errors!", WithStack())
+		err := Catch(func() error {
+			recoverPanicSite(orig)
+			return nil
+		})
+
+		chErr, ok := err.(ChainedError)
+		if !assert.True(t, ok, "expected ChainedError, got %T", err) {
+			return
+		}
+
+		assert.Equal(t, orig, chErr.Inner())
+		assert.Equal(t, "Hello Errors!, panic", fmt.Sprintf("%s", chErr))
+		assert.Equal(t, panicSite, chErr.Next().Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("chained panic value", func(t *testing.T) {
+		orig := NewChainString("outer").Chain(NewString("inner"))
+		err := Catch(func() error {
+			recoverPanicSite(orig)
+			return nil
+		})
+
+		assert.Equal(t, "outer, inner, panic", err.Error())
+		assert.Equal(t, "outer, inner", orig.Error(), "the panic value must not be modified")
+		assert.ErrorIs(t, err, orig.Next().Inner())
+
+		last := err.(ChainedError).Next().Next()
+		if assert.NotNil(t, last) {
+			assert.Equal(t, panicSite, last.Inner().StackTrace().Frames[0].Function)
+		}
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		sentinel := errors.New("sentinel")
+		assert.Nil(t, Catch(func() error { return nil }))
+		assert.Equal(t, sentinel, Catch(func() error { return sentinel }))
+	})
+}
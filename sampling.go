@@ -0,0 +1,96 @@
+package errstack
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingPolicy decides whether the stack of an error created with WithStack is captured. Sample receives
+// the program counter of the call site creating the error. Errors whose capture is skipped only record
+// that call site and report their stack trace as sampled out. Implementations must be safe for concurrent
+// use.
+type SamplingPolicy interface {
+	Sample(pc uintptr) bool
+}
+
+type SamplingPolicyFunc func(pc uintptr) bool
+
+func (self SamplingPolicyFunc) Sample(pc uintptr) bool {
+	return self(pc)
+}
+
+type samplingPolicyBox struct {
+	p SamplingPolicy
+}
+
+var activeSamplingPolicy atomic.Pointer[samplingPolicyBox]
+
+// SetSamplingPolicy installs p as the policy of the errors which weren't created with WithSampling and
+// returns the previous one. A nil p captures every stack, which is the default.
+func SetSamplingPolicy(p SamplingPolicy) SamplingPolicy {
+	prev := activeSamplingPolicy.Swap(&samplingPolicyBox{p: p})
+	if prev == nil {
+		return nil
+	}
+
+	return prev.p
+}
+
+func samplingPolicy() SamplingPolicy {
+	if box := activeSamplingPolicy.Load(); box != nil {
+		return box.p
+	}
+
+	return nil
+}
+
+// NewRateLimitPolicy captures the first n stacks of every call site in each interval
+func NewRateLimitPolicy(n int, interval time.Duration) SamplingPolicy {
+	return &rateLimitPolicy{
+		n:        n,
+		interval: interval,
+		sites:    make(map[uintptr]*rateLimitWindow),
+	}
+}
+
+// NewProbabilityPolicy captures a stack with probability p
+func NewProbabilityPolicy(p float64) SamplingPolicy {
+	return SamplingPolicyFunc(func(uintptr) bool {
+		return p >= 1 || p > 0 && rand.Float64() < p
+	})
+}
+
+type rateLimitPolicy struct {
+	mu       sync.Mutex
+	n        int
+	interval time.Duration
+	sites    map[uintptr]*rateLimitWindow
+}
+
+type rateLimitWindow struct {
+	start time.Time
+	count int
+}
+
+func (self *rateLimitPolicy) Sample(pc uintptr) bool {
+	now := time.Now()
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	window, ok := self.sites[pc]
+	if !ok {
+		window = &rateLimitWindow{start: now}
+		self.sites[pc] = window
+	}
+
+	if now.Sub(window.start) >= self.interval {
+		window.start, window.count = now, 0
+	}
+
+	window.count++
+
+	return window.count <= self.n
+}
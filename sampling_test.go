@@ -0,0 +1,83 @@
+package errstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Sampling(t *testing.T) {
+	never := SamplingPolicyFunc(func(uintptr) bool { return false })
+
+	t.Run("rate limit per call site", func(t *testing.T) {
+		defer SetSamplingPolicy(SetSamplingPolicy(NewRateLimitPolicy(2, time.Hour)))
+
+		errs := []*StacktraceError{}
+		for i := 0; i < 4; i++ {
+			errs = append(errs, NewString("storm", WithStack()))
+		}
+		other := NewString("other site", WithStack())
+
+		for i, err := range errs {
+			stackTrace := err.StackTrace()
+			assert.Equal(t, i >= 2, stackTrace.SampledOut, i)
+			assert.Equal(t, "github.com/nnishant776/errstack.Test_Sampling.func2", stackTrace.Frames[0].Function)
+			if stackTrace.SampledOut {
+				assert.Len(t, stackTrace.Frames, 1)
+			}
+		}
+
+		assert.False(t, other.StackTrace().SampledOut)
+	})
+
+	t.Run("per call override", func(t *testing.T) {
+		err := NewString("skipped", WithStack(), WithSampling(never))
+		assert.True(t, err.StackTrace().SampledOut)
+		assert.Len(t, err.StackTrace().Frames, 1)
+
+		defer SetSamplingPolicy(SetSamplingPolicy(never))
+		err = NewString("kept", WithStack(), WithSampling(NewProbabilityPolicy(1)))
+		assert.False(t, err.StackTrace().SampledOut)
+	})
+
+	t.Run("probability", func(t *testing.T) {
+		assert.False(t, NewProbabilityPolicy(0).Sample(0))
+		assert.True(t, NewProbabilityPolicy(1).Sample(0))
+	})
+
+	t.Run("formatting and json", func(t *testing.T) {
+		err := NewString("skipped", WithStack(), WithSampling(never))
+
+		assert.True(t, strings.HasSuffix(fmt.Sprintf("%+v", err), "\ntrace omitted (sampled)"))
+
+		b, _ := json.Marshal(err)
+		decoded := &StacktraceError{}
+		if assert.NoError(t, json.Unmarshal(b, decoded)) {
+			assert.Equal(t, withoutAddresses(err.StackTrace()), decoded.StackTrace())
+		}
+	})
+
+	t.Run("concurrent policy swap", func(t *testing.T) {
+		defer SetSamplingPolicy(SetSamplingPolicy(nil))
+
+		done := make(chan struct{})
+		for i := 0; i < 4; i++ {
+			go func(i int) {
+				if i%2 == 0 {
+					SetSamplingPolicy(NewRateLimitPolicy(1, time.Millisecond))
+				}
+				for j := 0; j < 16; j++ {
+					NewString("concurrent", WithStack()).StackTrace()
+				}
+				done <- struct{}{}
+			}(i)
+		}
+		for i := 0; i < 4; i++ {
+			<-done
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package errstack
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+)
+
+var _ slog.LogValuer = (*StacktraceError)(nil)
+var _ slog.LogValuer = (*ChainedStacktraceError)(nil)
+var _ slog.Handler = (*LogHandler)(nil)
+
+// DefaultLogMaxFrames is the number of frames attached by LogValue. A value <= 0 attaches all of them.
+var DefaultLogMaxFrames = 0
+
+func (self *StacktraceError) LogValue() slog.Value {
+	return errLogValue(self, DefaultLogMaxFrames)
+}
+
+func (self *ChainedStacktraceError) LogValue() slog.Value {
+	return errLogValue(self, DefaultLogMaxFrames)
+}
+
+type LogHandlerOptions struct {
+	// MaxFrames is the number of frames attached for every error. A value <= 0 attaches all of them.
+	MaxFrames int
+}
+
+// LogHandler wraps a slog.Handler and expands every error attribute of a record into a group carrying the
+// message, fields, stack frames and chain elements of the error.
+type LogHandler struct {
+	next slog.Handler
+	opts LogHandlerOptions
+}
+
+func NewLogHandler(next slog.Handler, opts *LogHandlerOptions) *LogHandler {
+	h := &LogHandler{
+		next: next,
+	}
+
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	return h
+}
+
+func (self *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return self.next.Enabled(ctx, level)
+}
+
+func (self *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	rec := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		rec.AddAttrs(self.expand(a))
+		return true
+	})
+
+	return self.next.Handle(ctx, rec)
+}
+
+func (self *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		expanded = append(expanded, self.expand(a))
+	}
+
+	return &LogHandler{
+		next: self.next.WithAttrs(expanded),
+		opts: self.opts,
+	}
+}
+
+func (self *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{
+		next: self.next.WithGroup(name),
+		opts: self.opts,
+	}
+}
+
+func (self *LogHandler) expand(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		expanded := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			expanded = append(expanded, self.expand(ga))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(expanded...)}
+
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := a.Value.Any().(error); ok && err != nil {
+			return slog.Attr{Key: a.Key, Value: errLogValue(err, self.opts.MaxFrames)}
+		}
+	}
+
+	return a
+}
+
+func errLogValue(err error, maxFrames int) slog.Value {
+	if err == nil {
+		return slog.StringValue(NilErrorString)
+	}
+
+	chErr, ok := err.(ChainedError)
+	if !ok {
+		return slog.GroupValue(errLogAttrs(err, maxFrames)...)
+	}
+
+	// A nil or an empty chain has no element to log
+	if chErr.Inner() == nil {
+		return slog.StringValue(NilErrorString)
+	}
+
+	elems := ([]slog.Attr)(nil)
+	for elem := chErr; elem != nil; elem = elem.Next() {
+		if elem.Inner() == nil {
+			continue
+		}
+
+		elems = append(elems, slog.Attr{
+			Key:   strconv.Itoa(len(elems)),
+			Value: slog.GroupValue(errLogAttrs(elem.Inner(), maxFrames)...),
+		})
+	}
+
+	attrs := []slog.Attr{slog.String("message", chErr.Error())}
+	if code := CodeOf(err); code != CodeNone {
+		attrs = append(attrs, slog.String("code", code.String()))
+	}
+
+	if fielder, ok := err.(Fielder); ok {
+		if fields := fieldsLogAttrs(fielder.Fields()); len(fields) > 0 {
+			attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fields...)})
+		}
+	}
+
+	return slog.GroupValue(append(attrs, slog.Attr{Key: "chain", Value: slog.GroupValue(elems...)})...)
+}
+
+func errLogAttrs(err error, maxFrames int) []slog.Attr {
+	attrs := []slog.Attr{slog.String("message", err.Error())}
+
+	if c, ok := err.(Coder); ok && c.Code() != CodeNone {
+		attrs = append(attrs, slog.String("code", c.Code().String()))
+	}
+
+	if fielder, ok := err.(Fielder); ok {
+		if fields := fieldsLogAttrs(fielder.Fields()); len(fields) > 0 {
+			attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fields...)})
+		}
+	}
+
+	if stErr, ok := err.(StackTracer); ok {
+		frames := stErr.StackTrace().Frames
+		if maxFrames > 0 {
+			frames = frames[:min(maxFrames, len(frames))]
+		}
+
+		if len(frames) > 0 {
+			frameList := make([]string, 0, len(frames))
+			for _, f := range frames {
+				frameList = append(frameList, f.String())
+			}
+			attrs = append(attrs, slog.Any("frames", frameList))
+		}
+	}
+
+	return attrs
+}
+
+func fieldsLogAttrs(fields map[string]any) []slog.Attr {
+	if len(fields) <= 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, slog.Any(k, fields[k]))
+	}
+
+	return attrs
+}
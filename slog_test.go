@@ -0,0 +1,72 @@
+package errstack
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LogValue(t *testing.T) {
+	logabc2 := func() Error {
+		return NewString("Error 2", WithStack(), WithFields(map[string]any{"user_id": 42}))
+	}
+	logabc1 := func() ChainedError {
+		return Chain(NewString("Error 1"), logabc2())
+	}
+
+	decode := func(t *testing.T, buf *bytes.Buffer) map[string]any {
+		out := map[string]any{}
+		if !assert.NoError(t, json.Unmarshal(buf.Bytes(), &out)) {
+			t.FailNow()
+		}
+		return out
+	}
+
+	t.Run("stacktrace error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		slog.New(slog.NewJSONHandler(buf, nil)).Error("failed", "err", logabc2())
+
+		errVal := decode(t, buf)["err"].(map[string]any)
+		assert.Equal(t, "Error 2", errVal["message"])
+		assert.Equal(t, map[string]any{"user_id": float64(42)}, errVal["fields"])
+		assert.NotEmpty(t, errVal["frames"])
+		assert.Contains(t, errVal["frames"].([]any)[0], "errstack.Test_LogValue.func1@")
+	})
+
+	t.Run("chained error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		slog.New(slog.NewJSONHandler(buf, nil)).Error("failed", "err", logabc1())
+
+		errVal := decode(t, buf)["err"].(map[string]any)
+		assert.Equal(t, "Error 1, Error 2", errVal["message"])
+
+		chain := errVal["chain"].(map[string]any)
+		assert.Equal(t, map[string]any{"message": "Error 1"}, chain["0"])
+		assert.Equal(t, "Error 2", chain["1"].(map[string]any)["message"])
+	})
+
+	t.Run("nil chained error", func(t *testing.T) {
+		assert.Equal(t, NilErrorString, (*ChainedStacktraceError)(nil).LogValue().String())
+		assert.Equal(t, NilErrorString, (&ChainedStacktraceError{}).LogValue().String())
+
+		buf := &bytes.Buffer{}
+		slog.New(NewLogHandler(slog.NewJSONHandler(buf, nil), nil)).Error("failed", "err", (*ChainedStacktraceError)(nil))
+		assert.Equal(t, NilErrorString, decode(t, buf)["err"])
+	})
+
+	t.Run("handler with max frames", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := NewLogHandler(slog.NewJSONHandler(buf, nil), &LogHandlerOptions{MaxFrames: 1})
+		slog.New(h).With("base", errors.New("plain")).WithGroup("req").Error("failed", "err", logabc1())
+
+		out := decode(t, buf)
+		assert.Equal(t, map[string]any{"message": "plain"}, out["base"])
+
+		chain := out["req"].(map[string]any)["err"].(map[string]any)["chain"].(map[string]any)
+		assert.Len(t, chain["1"].(map[string]any)["frames"], 1)
+	})
+}
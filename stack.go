@@ -2,20 +2,82 @@ package errstack
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"strings"
+	"sync/atomic"
 )
 
 var _ Error = (*StacktraceError)(nil)
 
+// StacktraceError is an error with a stack trace. Throwing a StacktraceError never modifies it. Instead,
+// every throw returns a new lightweight error pointing at the thrown one, which records only the program
+// counter of the throw. This keeps errors shared between goroutines, like sentinels, immutable.
 type StacktraceError struct {
+	*stackOrigin
+	parent     *StacktraceError
+	symbolized atomic.Pointer[StackTrace]
+	throwPC    uintptr
+	msg        string
+	msgPC      uintptr
+	fields     map[string]any
+	depth      int
+	truncated  bool
+}
+
+// stackOrigin holds what the errors thrown from an error share with it: the wrapped error, the options and
+// the stack captured or decoded at creation. It is allocated along with the error created first.
+type stackOrigin struct {
+	node       StacktraceError
 	err        error
 	str        string
-	stackTrace StackTrace
-	pcList     [_MAX_CALL_DEPTH]uintptr
 	opts       stackErrOpts
+	stackTrace StackTrace
+	pcInline   [_INLINE_CALL_DEPTH]uintptr
+	pcList     []uintptr
 	frameCount int
+	sampledOut bool
+	decoded    bool
+	isSentinel bool
+}
+
+func newOrigin(err error, errStr string, opts stackErrOpts) *StacktraceError {
+	origin := &stackOrigin{
+		err:  err,
+		str:  errStr,
+		opts: opts,
+	}
+	origin.node.stackOrigin = origin
+
+	return &origin.node
+}
+
+// newThrown creates the lightweight error recording a throw of parent
+func newThrown(parent *StacktraceError) *StacktraceError {
+	return &StacktraceError{
+		stackOrigin: parent.stackOrigin,
+		parent:      parent,
+		depth:       parent.depth,
+	}
+}
+
+// ownsOrigin reports whether the stack of the origin belongs to the error, rather than to an error it was
+// thrown from
+func (self *StacktraceError) ownsOrigin() bool {
+	return self.parent == nil || self.parent.stackOrigin != self.stackOrigin
+}
+
+// fieldMap returns the fields attached by the latest throw with fields, or the ones of the origin
+func (self *StacktraceError) fieldMap() map[string]any {
+	for elem := self; elem != nil; elem = elem.parent {
+		if elem.fields != nil {
+			return elem.fields
+		}
+	}
+
+	return self.opts.fields
 }
 
 func New(err error, opts ...StackErrOption) *StacktraceError {
@@ -26,48 +88,294 @@ func NewString(errStr string, opts ...StackErrOption) *StacktraceError {
 	return newStacktraceErrorString(errStr, opts...)
 }
 
-func newStacktraceError(err error, opts ...StackErrOption) *StacktraceError {
-	stErr := &StacktraceError{
-		err: err,
+// NewSentinel declares an error which is meant to be stored in a package level variable. Unlike the other
+// constructors, WithStack captures the stack trace on every throw of a sentinel instead of at creation.
+// Errors thrown from a sentinel match it through errors.Is.
+func NewSentinel(errStr string, opts ...StackErrOption) *StacktraceError {
+	stErr := newOrigin(nil, errStr, stackErrOpts{})
+	stErr.isSentinel = true
+
+	for _, f := range opts {
+		stErr.opts = f(stErr.opts)
 	}
 
+	return stErr
+}
+
+// NewFromStackTrace creates an error with an already symbolized stack trace, e.g. one received from another
+// process. WithStack has no effect on such errors.
+func NewFromStackTrace(errStr string, stackTrace StackTrace, opts ...StackErrOption) *StacktraceError {
+	stErr := newOrigin(nil, errStr, stackErrOpts{})
+	stErr.stackTrace = stackTrace
+	stErr.depth = len(stackTrace.Frames)
+	stErr.decoded = true
+
 	for _, f := range opts {
 		stErr.opts = f(stErr.opts)
 	}
 
-	if stErr.opts.autoStacktrace {
-		stErr.frameCount = len(callersPCsBuf(3, _MAX_CALL_DEPTH, stErr.pcList[:]))
+	return stErr
+}
+
+func newStacktraceError(err error, opts ...StackErrOption) *StacktraceError {
+	stErr := newOrigin(err, "", stackErrOpts{})
+
+	for _, f := range opts {
+		stErr.opts = f(stErr.opts)
+	}
+
+	// An error of another library carrying a stack already knows where it came from, unlike a stack
+	// captured here, which would point at the wrapping code
+	if stack, ok := extractStack(err); ok {
+		stErr.adoptStack(stack)
+	} else if stErr.opts.autoStacktrace {
+		stErr.capture(3)
 	}
 
 	return stErr
 }
 
 func newStacktraceErrorString(errStr string, opts ...StackErrOption) *StacktraceError {
-	stErr := &StacktraceError{
-		str: errStr,
-	}
+	stErr := newOrigin(nil, errStr, stackErrOpts{})
 
 	for _, f := range opts {
 		stErr.opts = f(stErr.opts)
 	}
 
 	if stErr.opts.autoStacktrace {
-		stErr.frameCount = len(callersPCsBuf(stErr.opts.extraFrameSkip+3, _MAX_CALL_DEPTH, stErr.pcList[:]))
+		stErr.capture(stErr.opts.extraFrameSkip + 3)
 	}
 
 	return stErr
 }
 
+func (self *StacktraceError) maxDepth() int {
+	if self.opts.maxDepth > 0 {
+		return self.opts.maxDepth
+	}
+
+	return max(1, DefaultMaxStackDepth)
+}
+
+func (self *StacktraceError) pcs() []uintptr {
+	if self.pcList != nil {
+		return self.pcList[:self.frameCount]
+	}
+
+	return self.pcInline[:self.frameCount]
+}
+
+func (self *StacktraceError) setPCs(pcs []uintptr) {
+	if len(pcs) <= _INLINE_CALL_DEPTH {
+		self.pcList = nil
+		self.frameCount = copy(self.pcInline[:], pcs)
+	} else {
+		self.pcList = append(make([]uintptr, 0, len(pcs)), pcs...)
+		self.frameCount = len(pcs)
+	}
+
+	self.depth = self.frameCount
+}
+
+func (self *StacktraceError) capture(skip int) {
+	policy := self.opts.sampling
+	if policy == nil {
+		policy = samplingPolicy()
+	}
+
+	if policy != nil {
+		site := [1]uintptr{}
+		if pcs := callersPCsBuf(skip+1, 1, site[:]); len(pcs) > 0 && !policy.Sample(pcs[0]) {
+			self.setPCs(pcs)
+			self.sampledOut = true
+			return
+		}
+	}
+
+	depth := self.maxDepth()
+
+	buf := [_MAX_CALL_DEPTH + 1]uintptr{}
+	pcs := buf[:]
+	if depth+1 > len(buf) {
+		pcs = make([]uintptr, depth+1)
+	}
+
+	pcs = callersPCsBuf(skip+1, depth+1, pcs)
+	if len(pcs) > depth {
+		pcs = pcs[:depth]
+		self.truncated = true
+	}
+
+	self.setPCs(pcs)
+}
+
+// adoptPCs uses program counters captured elsewhere as the stack of the error. Unlike a stack captured
+// by WithStack, it doesn't cover the frames the error goes through afterwards, so throws keep recording them.
+func (self *StacktraceError) adoptPCs(pcs []uintptr) {
+	self.opts.autoStacktrace = false
+	if len(pcs) > self.maxDepth() {
+		pcs = pcs[:self.maxDepth()]
+		self.truncated = true
+	}
+
+	self.setPCs(pcs)
+}
+
+func (self *StacktraceError) adoptStack(stack ExtractedStack) {
+	if len(stack.PCs) > 0 {
+		self.adoptPCs(stack.PCs)
+		return
+	}
+
+	self.opts.autoStacktrace = false
+	self.decoded = true
+	self.stackTrace = StackTrace{Frames: stack.Frames}
+	self.depth = len(stack.Frames)
+}
+
+// adoptError wraps an Error implemented outside of this package, keeping its stack trace, so that it can be
+// thrown from a frame chosen by the caller
+func adoptError(e Error) *StacktraceError {
+	stErr := newStacktraceError(e)
+	if frames := e.StackTrace().Frames; len(frames) > 0 {
+		stErr.adoptStack(ExtractedStack{Frames: frames})
+	}
+
+	return stErr
+}
+
+func (self *StacktraceError) root() *StacktraceError {
+	root := self
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	return root
+}
+
+// genStackTrace symbolizes the program counters of the error and of all the errors it was thrown from. A
+// program counter recorded by a throw only contributes the innermost frame, even if the call site was
+// inlined into its callers.
+func (self *StacktraceError) genStackTrace(n int) StackTrace {
+	chain := make([]*StacktraceError, 0, 8)
+	for elem := self; elem != nil; elem = elem.parent {
+		chain = append(chain, elem)
+	}
+
+	stackTrace := StackTrace{
+		Frames: make([]Frame, 0, min(n, self.depth)),
+	}
+
+	for i := len(chain) - 1; i >= 0 && len(stackTrace.Frames) < n; i-- {
+		elem := chain[i]
+		stackTrace.Truncated = stackTrace.Truncated || elem.truncated
+
+		switch {
+		case elem.throwPC != 0:
+			frame := caller0(elem.throwPC)
+			frame.Message = elem.msg
+			stackTrace.Frames = append(stackTrace.Frames, elem.trimPaths([]Frame{frame})...)
+		case !elem.ownsOrigin():
+			// A throw which didn't record a frame, e.g. past the maximum depth
+		case elem.decoded:
+			stackTrace.Frames = append(stackTrace.Frames, elem.stackTrace.Frames...)
+			stackTrace.Truncated = stackTrace.Truncated || elem.stackTrace.Truncated
+			stackTrace.SampledOut = stackTrace.SampledOut || elem.stackTrace.SampledOut
+		case elem.sampledOut:
+			stackTrace.Frames = append(stackTrace.Frames, elem.trimPaths([]Frame{caller0(elem.pcs()[0])})...)
+			stackTrace.SampledOut = true
+		default:
+			stackTrace.Frames = append(stackTrace.Frames, elem.trimPaths(genStackTraceFromPCs(elem.pcs()))...)
+		}
+
+		if elem.msgPC != 0 {
+			stackTrace.Frames = elem.annotate(stackTrace.Frames)
+		}
+	}
+
+	if len(stackTrace.Frames) > n {
+		stackTrace.Frames = stackTrace.Frames[:n]
+		stackTrace.Truncated = true
+	}
+
+	return stackTrace
+}
+
+// annotate binds the message of the error to the frame of the function it was thrown from, for errors
+// whose throw didn't record a frame of its own. The frame is added if the function isn't in frames.
+func (self *StacktraceError) annotate(frames []Frame) []Frame {
+	frame := caller0(self.msgPC)
+
+	for i := range frames {
+		if frames[i].Function != frame.Function {
+			continue
+		}
+
+		if frames[i].Message != "" {
+			frames[i].Message = self.msg + ErrorChainSeparator + frames[i].Message
+		} else {
+			frames[i].Message = self.msg
+		}
+
+		return frames
+	}
+
+	frame.Message = self.msg
+
+	return append(frames, self.trimPaths([]Frame{frame})...)
+}
+
+func (self *StacktraceError) trimPaths(frames []Frame) []Frame {
+	if self.opts.pathTrimmer == nil {
+		return frames
+	}
+
+	for i := range frames {
+		frames[i].File = self.opts.pathTrimmer.TrimPath(frames[i])
+	}
+
+	return frames
+}
+
+func (self *StacktraceError) Truncated() bool {
+	if self == nil {
+		return false
+	}
+
+	for elem := self; elem != nil; elem = elem.parent {
+		if elem.truncated || elem.decoded && elem.stackTrace.Truncated {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (self *StacktraceError) Error() string {
 	if self == nil {
 		return NilErrorString
 	}
 
+	errStr := self.str
 	if self.err != nil {
-		return self.err.Error()
+		errStr = self.err.Error()
 	}
 
-	return self.str
+	sb := strings.Builder{}
+	for elem := self; elem != nil; elem = elem.parent {
+		if elem.msg != "" {
+			sb.WriteString(elem.msg)
+			sb.WriteString(ErrorChainSeparator)
+		}
+	}
+
+	if sb.Len() <= 0 {
+		return errStr
+	}
+
+	sb.WriteString(errStr)
+
+	return sb.String()
 }
 
 //go:noinline
@@ -76,21 +384,32 @@ func (self *StacktraceError) ThrowSkip(skip int) Error {
 		return nil
 	}
 
-	if self.opts.autoStacktrace {
+	skipCnt := 1 + max(0, skip)
+
+	if self.opts.autoStacktrace && !self.isSentinel {
 		return self
 	}
 
-	skipCnt := 1 + max(0, skip)
+	if self.isSentinel && self.opts.autoStacktrace {
+		thrown := newOrigin(self.err, self.str, self.opts)
+		thrown.parent = self
+		thrown.capture(skipCnt + 1)
+		return thrown
+	}
 
-	if pc := callerPC(skipCnt); pc != math.MaxUint64 {
-		if self.frameCount < _MAX_CALL_DEPTH {
-			self.pcList[self.frameCount] = pc
-			self.frameCount++
+	thrown := newThrown(self)
+
+	switch {
+	case thrown.depth >= thrown.maxDepth():
+		thrown.truncated = true
+	default:
+		if pc := callerPC(skipCnt); pc != math.MaxUint64 {
+			thrown.throwPC = pc
+			thrown.depth++
 		}
-		self.stackTrace = StackTrace{}
 	}
 
-	return self
+	return thrown
 }
 
 //go:noinline
@@ -98,30 +417,145 @@ func (self *StacktraceError) Throw() Error {
 	return self.ThrowSkip(1)
 }
 
-func (self *StacktraceError) StackTraceN(n int) StackTrace {
+//go:noinline
+func (self *StacktraceError) ThrowWithFields(fields map[string]any) Error {
+	return self.throwWithFields(1, fields)
+}
+
+//go:noinline
+func (self *StacktraceError) throwWithFields(skip int, fields map[string]any) Error {
 	if self == nil {
-		return StackTrace{}
+		return nil
 	}
 
-	n = min(n, self.frameCount)
+	thrown := self.ThrowSkip(skip + 1).(*StacktraceError)
+	if thrown == self {
+		thrown = newThrown(self)
+	}
 
-	self.stackTrace.Frames = genStackTraceFromPCs(self.pcList[:n])
+	thrown.fields = mergeFields(self.fieldMap(), fields)
 
-	return self.stackTrace
+	return thrown
+}
+
+// ThrowMsg throws the error with an annotation describing what was being done at the throw, e.g. "loading
+// config". The annotation is prepended to the error string and bound to the frame of the throw.
+//
+//go:noinline
+func (self *StacktraceError) ThrowMsg(msg string) Error {
+	return self.throwMsg(1, msg)
+}
+
+//go:noinline
+func (self *StacktraceError) throwMsg(skip int, msg string) Error {
+	if self == nil {
+		return nil
+	}
+
+	thrown := self.ThrowSkip(skip + 1).(*StacktraceError)
+	if thrown == self {
+		thrown = newThrown(self)
+	}
+
+	thrown.msg = msg
+
+	if thrown.throwPC == 0 {
+		if pc := callerPC(skip + 1); pc != math.MaxUint64 {
+			thrown.msgPC = pc
+		}
+	}
+
+	return thrown
+}
+
+// Fields returns a copy of the fields attached to the error, which can be modified freely
+func (self *StacktraceError) Fields() map[string]any {
+	if self == nil {
+		return nil
+	}
+
+	return maps.Clone(self.fieldMap())
+}
+
+func (self *StacktraceError) Code() Code {
+	if self == nil {
+		return CodeNone
+	}
+
+	return self.opts.code
+}
+
+func (self *StacktraceError) StackTraceN(n int) StackTrace {
+	if self == nil || n <= 0 {
+		return StackTrace{}
+	}
+
+	stackTrace := self.StackTrace()
+	if len(stackTrace.Frames) <= n {
+		return stackTrace
+	}
+
+	return StackTrace{
+		Frames:     stackTrace.Frames[:n:n],
+		Truncated:  true,
+		SampledOut: stackTrace.SampledOut,
+	}
 }
 
 func (self *StacktraceError) StackTrace() StackTrace {
-	if self == nil || self.frameCount <= 0 {
+	if self == nil {
 		return StackTrace{}
 	}
 
-	if len(self.stackTrace.Frames) > 0 {
+	if self.decoded && self.ownsOrigin() {
 		return self.stackTrace
 	}
 
-	self.stackTrace.Frames = genStackTraceFromPCs(self.pcList[:self.frameCount])
+	if self.depth <= 0 && !self.Truncated() {
+		return StackTrace{}
+	}
+
+	if stackTrace := self.symbolized.Load(); stackTrace != nil {
+		return *stackTrace
+	}
+
+	// Concurrent callers may symbolize the error more than once, which is cheaper than synchronizing them
+	// since the frames come from the frame cache
+	stackTrace := self.genStackTrace(math.MaxInt)
+	self.symbolized.Store(&stackTrace)
+
+	return stackTrace
+}
+
+// Is reports whether the error matches target. An error matches every error it was thrown from as well as
+// every other error thrown from the same origin. Errors created with WithSentinel also match the sentinel
+// and errors created with WithCode match the code.
+func (self *StacktraceError) Is(target error) bool {
+	if self == nil {
+		return false
+	}
+
+	if code, ok := target.(Code); ok {
+		return code != CodeNone && CodeOf(self) == code
+	}
+
+	root := self.root()
 
-	return self.stackTrace
+	if t, ok := target.(*StacktraceError); ok && t != nil && t.root() == root {
+		return true
+	}
+
+	// errors.Is only compares comparable errors, so sentinels like structs holding a slice don't panic
+	return self.opts.sentinel != nil && errors.Is(self.opts.sentinel, target)
+}
+
+// As finds the first error in the sentinel of the error that matches target
+func (self *StacktraceError) As(target any) bool {
+	if self == nil || self.opts.sentinel == nil {
+		return false
+	}
+
+	return errors.As(self.opts.sentinel, target)
 }
 
 func (self *StacktraceError) Unwrap() error {
@@ -141,13 +575,74 @@ func (self *StacktraceError) MarshalJSON() ([]byte, error) {
 		"error": self.Error(),
 	}
 
-	if self.frameCount > 0 {
-		data["trace"] = self.StackTrace()
+	if self.opts.code != CodeNone {
+		data["code"] = self.opts.code
+	}
+
+	if fields := self.fieldMap(); len(fields) > 0 {
+		data["fields"] = fields
+	}
+
+	if DefaultFingerprintOptions.InJSON {
+		data["fingerprint"] = self.Fingerprint()
+	}
+
+	stackTrace := self.StackTrace()
+	if len(stackTrace.Frames) <= 0 {
+		stackTrace, _ = ExtractStackTrace(self.err)
+	}
+
+	if len(stackTrace.Frames) > 0 {
+		data["trace"] = stackTrace
 	}
 
 	return json.Marshal(data)
 }
 
+// UnmarshalJSON rebuilds the error from the output of MarshalJSON. The decoded frames are used as the
+// stack trace of the error, since the program counters of the original process are meaningless here.
+func (self *StacktraceError) UnmarshalJSON(data []byte) error {
+	if self == nil {
+		return errNilUnmarshalTarget
+	}
+
+	if string(data) == "null" {
+		return nil
+	}
+
+	payload := struct {
+		Error  *string        `json:"error"`
+		Code   Code           `json:"code"`
+		Fields map[string]any `json:"fields"`
+		Trace  *StackTrace    `json:"trace"`
+	}{}
+
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	if payload.Error == nil {
+		return errMissingErrorField
+	}
+
+	*self = StacktraceError{
+		stackOrigin: &stackOrigin{
+			str:     *payload.Error,
+			decoded: true,
+		},
+	}
+
+	self.opts.code = payload.Code
+	self.opts.fields = payload.Fields
+
+	if payload.Trace != nil {
+		self.stackTrace = *payload.Trace
+		self.depth = len(self.stackTrace.Frames)
+	}
+
+	return nil
+}
+
 func (self *StacktraceError) String() string {
 	if self == nil {
 		return NilErrorString
@@ -178,7 +673,8 @@ func (self *StacktraceError) String() string {
 //	%+v	Same as %-v, except it will print the stack trace on a separate line than the error. This
 //		overrides the stack frame separator and the error separator to '\n'. Rest of the options
 //		are kept intact. '+' can be followed by an arbitrary number which will represent the count
-//		of spaces used to indent the stack trace
+//		of spaces used to indent the stack trace. Fields attached to the error are printed after
+//		the error string
 //
 //	%#v	Same as %+(n)v, except it will print stack indices as well
 //
@@ -226,6 +722,10 @@ func (self *StacktraceError) Format(s fmt.State, verb rune) {
 		stFmt = stFmt.Copy()
 		ffFmt = ffFmt.Copy()
 
+		if flags&0x0c > 0 {
+			eOpts.ShowFields = true
+		}
+
 		if flags&0x0d > 0 {
 			eOpts.StackTraceSeparator = "\n"
 			sOpts.FrameSeparator = "\n"
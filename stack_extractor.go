@@ -0,0 +1,247 @@
+package errstack
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// StackExtractor reads the stack trace carried by the errors of another library. PCs should be returned
+// whenever the library exposes them, since they are symbolized with the active Capturer like the stacks
+// captured by the package. Frames are used as is otherwise.
+type StackExtractor interface {
+	// ExtractStack inspects err itself, not the errors it wraps, and reports whether it carries a stack
+	ExtractStack(err error) (ExtractedStack, bool)
+}
+
+type ExtractedStack struct {
+	PCs    []uintptr
+	Frames []Frame
+}
+
+type StackExtractorFunc func(err error) (ExtractedStack, bool)
+
+func (self StackExtractorFunc) ExtractStack(err error) (ExtractedStack, bool) {
+	return self(err)
+}
+
+var (
+	extractorsMu        sync.Mutex
+	registeredExtractor atomic.Pointer[[]StackExtractor]
+)
+
+// builtinStackExtractors understand github.com/go-errors/errors, as well as github.com/pkg/errors and the
+// libraries mirroring it, like cockroachdb/errors and emperror, through reflection. The errstack/pkgerrors
+// package registers an extractor reading pkg/errors without it.
+var builtinStackExtractors = []StackExtractor{
+	StackExtractorFunc(extractCallersStack),
+	StackExtractorFunc(extractStackFramesStack),
+	StackExtractorFunc(extractErrorStackStack),
+	StackExtractorFunc(extractUintptrStackTrace),
+}
+
+// RegisterStackExtractor adds e to the extractors consulted for the errors of other libraries. The
+// registered extractors are consulted in registration order, before the built-in ones.
+func RegisterStackExtractor(e StackExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	extractors := []StackExtractor{}
+	if prev := registeredExtractor.Load(); prev != nil {
+		extractors = append(extractors, *prev...)
+	}
+	extractors = append(extractors, e)
+
+	registeredExtractor.Store(&extractors)
+}
+
+// ExtractStackTrace returns the stack trace carried by the innermost error of the chain of err which is
+// understood by a StackExtractor. The errors of this package are skipped.
+func ExtractStackTrace(err error) (StackTrace, bool) {
+	stack, ok := extractStack(err)
+	if !ok {
+		return StackTrace{}, false
+	}
+
+	if len(stack.PCs) > 0 {
+		return StackTrace{Frames: genStackTraceFromPCs(stack.PCs)}, true
+	}
+
+	return StackTrace{Frames: stack.Frames}, true
+}
+
+func extractStack(err error) (ExtractedStack, bool) {
+	found, ok := ExtractedStack{}, false
+
+	for ; err != nil; err = unwrapForeign(err) {
+		switch err.(type) {
+		case Error, ChainedError:
+			continue
+		}
+
+		if stack, extracted := extractOne(err); extracted {
+			found, ok = stack, true
+		}
+	}
+
+	return found, ok
+}
+
+func unwrapForeign(err error) error {
+	if causer, ok := err.(interface{ Cause() error }); ok {
+		return causer.Cause()
+	}
+
+	return errors.Unwrap(err)
+}
+
+func extractOne(err error) (ExtractedStack, bool) {
+	if registered := registeredExtractor.Load(); registered != nil {
+		for _, e := range *registered {
+			if stack, ok := e.ExtractStack(err); ok && (len(stack.PCs) > 0 || len(stack.Frames) > 0) {
+				return stack, true
+			}
+		}
+	}
+
+	for _, e := range builtinStackExtractors {
+		if stack, ok := e.ExtractStack(err); ok && (len(stack.PCs) > 0 || len(stack.Frames) > 0) {
+			return stack, true
+		}
+	}
+
+	return ExtractedStack{}, false
+}
+
+// extractCallersStack reads the return program counters of github.com/go-errors/errors
+func extractCallersStack(err error) (ExtractedStack, bool) {
+	callers, ok := err.(interface{ Callers() []uintptr })
+	if !ok {
+		return ExtractedStack{}, false
+	}
+
+	return ExtractedStack{PCs: callers.Callers()}, true
+}
+
+// extractStackFramesStack reads a StackFrames method returning structs, like the StackFrame of
+// github.com/go-errors/errors. The program counters are used when the structs have a ProgramCounter or a PC
+// field, and the File, LineNumber or Line, and Name or Function fields otherwise.
+func extractStackFramesStack(err error) (ExtractedStack, bool) {
+	method := reflect.ValueOf(err).MethodByName("StackFrames")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ExtractedStack{}, false
+	}
+
+	if t := method.Type().Out(0); t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Struct {
+		return ExtractedStack{}, false
+	}
+
+	stackFrames := method.Call(nil)[0]
+	stack := ExtractedStack{}
+
+	for i := 0; i < stackFrames.Len(); i++ {
+		sf := stackFrames.Index(i)
+
+		if pc := firstField(sf, "ProgramCounter", "PC"); pc.IsValid() && pc.Kind() == reflect.Uintptr && pc.Uint() != 0 {
+			stack.PCs = append(stack.PCs, uintptr(pc.Uint()))
+			continue
+		}
+
+		f := Frame{}
+		if v := firstField(sf, "File"); v.IsValid() && v.Kind() == reflect.String {
+			f.File = v.String()
+		}
+		if v := firstField(sf, "LineNumber", "Line"); v.IsValid() && v.CanInt() {
+			f.Line = int(v.Int())
+		}
+		if v := firstField(sf, "Function", "Name"); v.IsValid() && v.Kind() == reflect.String {
+			f.Function = v.String()
+		}
+		if v := firstField(sf, "Package"); v.IsValid() && v.Kind() == reflect.String && v.String() != "" {
+			f.Function = v.String() + "." + f.Function
+		}
+		stack.Frames = append(stack.Frames, f)
+	}
+
+	if len(stack.PCs) > 0 && len(stack.Frames) > 0 {
+		stack.Frames = nil
+	}
+
+	return stack, true
+}
+
+var reErrorStackLocation = regexp.MustCompile(`^(.+):(\d+) \(0x[0-9a-f]+\)$`)
+
+// extractErrorStackStack parses the output of an ErrorStack method in the format of
+// github.com/go-errors/errors, where every frame spans two lines:
+//
+//	/src/app/main.go:12 (0x4a2f31)
+//		main: return run()
+func extractErrorStackStack(err error) (ExtractedStack, bool) {
+	errStack, ok := err.(interface{ ErrorStack() string })
+	if !ok {
+		return ExtractedStack{}, false
+	}
+
+	stack := ExtractedStack{}
+	lines := strings.Split(errStack.ErrorStack(), "\n")
+
+	for i, line := range lines {
+		m := reErrorStackLocation.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		f := Frame{File: m[1]}
+		f.Line, _ = strconv.Atoi(m[2])
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+			f.Function, _, _ = strings.Cut(strings.TrimPrefix(lines[i+1], "\t"), ": ")
+		}
+
+		stack.Frames = append(stack.Frames, f)
+	}
+
+	return stack, true
+}
+
+// extractUintptrStackTrace reads a StackTrace method returning a slice of program counters with a named
+// type, which is how the libraries mirroring github.com/pkg/errors define their frames
+func extractUintptrStackTrace(err error) (ExtractedStack, bool) {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ExtractedStack{}, false
+	}
+
+	if t := method.Type().Out(0); t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return ExtractedStack{}, false
+	}
+
+	frames := method.Call(nil)[0]
+	stack := ExtractedStack{PCs: make([]uintptr, 0, frames.Len())}
+	for i := 0; i < frames.Len(); i++ {
+		stack.PCs = append(stack.PCs, uintptr(frames.Index(i).Uint()))
+	}
+
+	return stack, true
+}
+
+func firstField(v reflect.Value, names ...string) reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	for _, name := range names {
+		if f := v.FieldByName(name); f.IsValid() {
+			return f
+		}
+	}
+
+	return reflect.Value{}
+}
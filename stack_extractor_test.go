@@ -0,0 +1,115 @@
+package errstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGoError struct {
+	pcs []uintptr
+}
+
+func newFakeGoError() *fakeGoError {
+	return &fakeGoError{pcs: callersPCs(2, _MAX_CALL_DEPTH)}
+}
+
+func (self *fakeGoError) Error() string      { return "go-errors" }
+func (self *fakeGoError) Callers() []uintptr { return self.pcs }
+
+type fakeStackFrame struct {
+	File       string
+	LineNumber int
+	Name       string
+	Package    string
+}
+
+type fakeStackFramesError struct{}
+
+func (fakeStackFramesError) Error() string { return "stack frames" }
+func (fakeStackFramesError) StackFrames() []fakeStackFrame {
+	return []fakeStackFrame{{File: "/src/app/db.go", LineNumber: 12, Name: "(*DB).Query", Package: "github.com/acme/app"}}
+}
+
+type fakeErrorStackError struct{}
+
+func (fakeErrorStackError) Error() string { return "error stack" }
+func (fakeErrorStackError) ErrorStack() string {
+	return "*errors.Error error stack\n/src/app/main.go:7 (0x4a2f31)\n\tmain: return run()\n/src/app/run.go:3 (0x4a2e10)\n\trun: \n"
+}
+
+type fakeFramePC uintptr
+
+type fakeUintptrError struct {
+	pcs []uintptr
+}
+
+func (self fakeUintptrError) Error() string { return "uintptr frames" }
+func (self fakeUintptrError) StackTrace() []fakeFramePC {
+	frames := []fakeFramePC{}
+	for _, pc := range self.pcs {
+		frames = append(frames, fakeFramePC(pc))
+	}
+	return frames
+}
+
+type fakeCustomError struct{}
+
+func (fakeCustomError) Error() string { return "custom" }
+
+func Test_StackExtractor(t *testing.T) {
+	thisFunc := "github.com/nnishant776/errstack.Test_StackExtractor"
+
+	t.Run("callers", func(t *testing.T) {
+		err := New(fmt.Errorf("wrapped: %w", newFakeGoError()))
+		assert.Equal(t, thisFunc+".func1", err.StackTrace().Frames[0].Function)
+	})
+
+	t.Run("stack frames", func(t *testing.T) {
+		err := New(fakeStackFramesError{})
+		assert.Equal(t, []Frame{{Function: "github.com/acme/app.(*DB).Query", File: "/src/app/db.go", Line: 12}}, err.StackTrace().Frames)
+	})
+
+	t.Run("error stack", func(t *testing.T) {
+		stackTrace, ok := ExtractStackTrace(fakeErrorStackError{})
+		assert.True(t, ok)
+		assert.Equal(t, []Frame{
+			{Function: "main", File: "/src/app/main.go", Line: 7},
+			{Function: "run", File: "/src/app/run.go", Line: 3},
+		}, stackTrace.Frames)
+	})
+
+	t.Run("uintptr stack trace", func(t *testing.T) {
+		chErr := Chain(NewString("outer"), fakeUintptrError{pcs: callersPCs(1, _MAX_CALL_DEPTH)})
+		assert.Equal(t, thisFunc+".func4", chErr.Next().Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("registered extractor", func(t *testing.T) {
+		chErr := NewChainString("outer").Chain(fakeCustomError{})
+		assert.NotContains(t, fmt.Sprintf("%+v", chErr), "custom.go")
+
+		RegisterStackExtractor(StackExtractorFunc(func(err error) (ExtractedStack, bool) {
+			if _, ok := err.(fakeCustomError); !ok {
+				return ExtractedStack{}, false
+			}
+			return ExtractedStack{Frames: []Frame{{Function: "custom.Func", File: "/src/custom.go", Line: 1}}}, true
+		}))
+
+		assert.True(t, strings.HasSuffix(fmt.Sprintf("%+v", chErr), "custom\ncustom.Func@/src/custom.go:1"))
+
+		b, _ := json.Marshal(chErr.Next().Inner())
+		assert.Contains(t, string(b), `"function":"custom.Func"`)
+
+		assert.Equal(t, "custom.Func", New(fakeCustomError{}).StackTrace().Frames[0].Function)
+	})
+
+	t.Run("no stack", func(t *testing.T) {
+		_, ok := ExtractStackTrace(fmt.Errorf("plain"))
+		assert.False(t, ok)
+		_, ok = ExtractStackTrace(NewString("own stack", WithStack()))
+		assert.False(t, ok)
+	})
+}
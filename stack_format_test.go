@@ -0,0 +1,148 @@
+package errstack_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nnishant776/errstack"
+	"github.com/nnishant776/errstack/errstacktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StackTracedErrorWithDefaults(t *testing.T) {
+	t.Run("automatic stacktrace collection", func(t *testing.T) {
+		stackabc3 := func() errstack.Error {
+			return errstack.New(errors.New("Hello Errors!"), errstack.WithStack())
+		}
+		stackabc2 := func() errstack.Error {
+			return stackabc3()
+		}
+		stackabc1 := func() errstack.Error {
+			return stackabc2()
+		}
+
+		err := stackabc1()
+
+		t.Run("printing formats", func(t *testing.T) {
+			cases := []struct {
+				format string
+				expect string
+			}{
+				{
+					format: "s",
+					expect: "Hello Errors!",
+				},
+				{
+					format: "+s",
+					expect: "Hello Errors!",
+				},
+				{
+					format: "v",
+					expect: "Hello Errors!=>github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.1;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.2;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.3;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1",
+				},
+				{
+					format: " v",
+					expect: "Hello Errors!\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.1\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.2\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.3\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1",
+				},
+				{
+					format: "-v",
+					expect: "Hello Errors!=>github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.1@stack_format_test.go:0;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.2@stack_format_test.go:0;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.3@stack_format_test.go:0;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1@stack_format_test.go:0",
+				},
+				{
+					format: "+v",
+					expect: "Hello Errors!\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.1@stack_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.2@stack_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.3@stack_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1@stack_format_test.go:0",
+				},
+				{
+					format: "#v",
+					expect: "Hello Errors!\n#5: github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.1@stack_format_test.go:0\n#4: github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.2@stack_format_test.go:0\n#3: github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1.3@stack_format_test.go:0\n#2: github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func1@stack_format_test.go:0",
+				},
+			}
+
+			for _, c := range cases {
+				t.Run(c.format, func(t *testing.T) {
+					assert.Equal(t, c.expect, errstacktest.SprintfIndexed("%"+c.format, err), "Doesn't match the expected output")
+				})
+			}
+
+			assertJSONFormats(t, err, map[string]any{"error": err.Error(), "trace": err.StackTrace()})
+		})
+	})
+
+	t.Run("manual stacktrace collection", func(t *testing.T) {
+		stackabc3 := func() errstack.Error {
+			return errstack.New(errors.New("Hello Errors!")).Throw()
+		}
+		stackabc2 := func() errstack.Error {
+			return stackabc3().Throw()
+		}
+		stackabc1 := func() errstack.Error {
+			return stackabc2().Throw()
+		}
+
+		err := stackabc1().Throw()
+
+		t.Run("printing formats", func(t *testing.T) {
+			cases := []struct {
+				format string
+				expect string
+			}{
+				{
+					format: "s",
+					expect: "Hello Errors!",
+				},
+				{
+					format: "+s",
+					expect: "Hello Errors!",
+				},
+				{
+					format: "v",
+					expect: "Hello Errors!=>github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.1;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.2;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.3;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2",
+				},
+				{
+					format: " v",
+					expect: "Hello Errors!\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.1\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.2\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.3\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2",
+				},
+				{
+					format: "-v",
+					expect: "Hello Errors!=>github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.1@stack_format_test.go:0;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.2@stack_format_test.go:0;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.3@stack_format_test.go:0;github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2@stack_format_test.go:0",
+				},
+				{
+					format: "+v",
+					expect: "Hello Errors!\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.1@stack_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.2@stack_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.3@stack_format_test.go:0\ngithub.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2@stack_format_test.go:0",
+				},
+				{
+					format: "#v",
+					expect: "Hello Errors!\n#3: github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.1@stack_format_test.go:0\n#2: github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.2@stack_format_test.go:0\n#1: github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2.3@stack_format_test.go:0\n#0: github.com/nnishant776/errstack_test.Test_StackTracedErrorWithDefaults.func2@stack_format_test.go:0",
+				},
+			}
+
+			for _, c := range cases {
+				t.Run(c.format, func(t *testing.T) {
+					assert.Equal(t, c.expect, errstacktest.SprintfIndexed("%"+c.format, err), "Doesn't match the expected output")
+				})
+			}
+
+			assertJSONFormats(t, err, map[string]any{"error": err.Error(), "trace": err.StackTrace()})
+		})
+	})
+}
+
+// assertJSONFormats checks the output of the json verbs of err against the encoding of expect
+func assertJSONFormats(t *testing.T, err error, expect any) {
+	t.Helper()
+
+	cases := map[string]string{"j": "", "+j": "  ", "+4j": "    "}
+
+	for format, indent := range cases {
+		t.Run(format, func(t *testing.T) {
+			b, _ := json.Marshal(expect)
+			if indent != "" {
+				b, _ = json.MarshalIndent(expect, "", indent)
+			}
+
+			assert.Equal(t, string(b)+"\n", fmt.Sprintf("%"+format, err), "Doesn't match the expected output")
+		})
+	}
+}
@@ -3,6 +3,12 @@ package errstack
 type stackErrOpts struct {
 	extraFrameSkip int
 	autoStacktrace bool
+	fields         map[string]any
+	maxDepth       int
+	pathTrimmer    PathTrimmer
+	sentinel       error
+	code           Code
+	sampling       SamplingPolicy
 }
 
 type StackErrOption func(stackErrOpts) stackErrOpts
@@ -20,3 +26,49 @@ func withExtraFrameSkip(n int) StackErrOption {
 		return o
 	}
 }
+
+func WithFields(fields map[string]any) StackErrOption {
+	return func(o stackErrOpts) stackErrOpts {
+		o.fields = mergeFields(o.fields, fields)
+		return o
+	}
+}
+
+func WithMaxDepth(n int) StackErrOption {
+	return func(o stackErrOpts) stackErrOpts {
+		o.maxDepth = max(n, 0)
+		return o
+	}
+}
+
+// WithPathTrimmer rewrites the file paths of the frames when the stack trace of the error is generated
+func WithPathTrimmer(t PathTrimmer) StackErrOption {
+	return func(o stackErrOpts) stackErrOpts {
+		o.pathTrimmer = t
+		return o
+	}
+}
+
+// WithSentinel gives an identity to the error, so that errors.Is reports true for target. It is mostly
+// useful for errors created with NewString, which otherwise have no identity apart from their pointer.
+func WithSentinel(target error) StackErrOption {
+	return func(o stackErrOpts) stackErrOpts {
+		o.sentinel = target
+		return o
+	}
+}
+
+func WithCode(code Code) StackErrOption {
+	return func(o stackErrOpts) stackErrOpts {
+		o.code = code
+		return o
+	}
+}
+
+// WithSampling overrides the global sampling policy for the error
+func WithSampling(p SamplingPolicy) StackErrOption {
+	return func(o stackErrOpts) stackErrOpts {
+		o.sampling = p
+		return o
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"runtime"
 	"strings"
 	"testing"
 
@@ -14,196 +12,225 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_StackTracedErrorWithDefaults(t *testing.T) {
-	goRoot := runtime.GOROOT()
-	currDir, err := os.Getwd()
-	if err != nil {
-		t.Errorf("Failed to get current directory: error: %s", err)
+func Test_StacktraceErrorJSONRoundTrip(t *testing.T) {
+	stackabc2 := func() Error {
+		return NewString("Hello Errors!", WithStack())
 	}
+	stackabc1 := func() Error {
+		return stackabc2()
+	}
+
+	cases := map[string]Error{
+		"automatic stacktrace": stackabc1(),
+		"manual stacktrace":    New(errors.New("Hello Errors!")).Throw().Throw(),
+		"no stacktrace":        NewString("Hello Errors!"),
+	}
+
+	for name, err := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoded := fmt.Sprintf("%j", err)
+
+			decoded := &StacktraceError{}
+			if !assert.NoError(t, json.Unmarshal([]byte(encoded), decoded)) {
+				return
+			}
 
-	t.Run("automatic stacktrace collection", func(t *testing.T) {
-		stackabc3 := func() Error {
-			return New(errors.New("Hello Errors!"), WithStack())
+			assert.Equal(t, err.Error(), decoded.Error())
+			assert.Equal(t, withoutAddresses(err.StackTrace()), decoded.StackTrace())
+			assert.Equal(t, encoded, fmt.Sprintf("%j", decoded))
+			assert.Equal(t, fmt.Sprintf("%#v", err), fmt.Sprintf("%#v", decoded))
+			assert.True(t, errors.Is(decoded, decoded))
+		})
+	}
+
+	t.Run("throw after decoding", func(t *testing.T) {
+		decoded := &StacktraceError{}
+		if !assert.NoError(t, json.Unmarshal([]byte(`{"error":"Hello Errors!"}`), decoded)) {
+			return
 		}
-		stackabc2 := func() Error {
-			return stackabc3()
+
+		frames := decoded.Throw().StackTrace().Frames
+		if assert.Len(t, frames, 1) {
+			assert.Equal(t, "github.com/nnishant776/errstack.Test_StacktraceErrorJSONRoundTrip.func4", frames[0].Function)
 		}
-		stackabc1 := func() Error {
-			return stackabc2()
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		assert.Error(t, json.Unmarshal([]byte(`{"trace":{}}`), &StacktraceError{}))
+		assert.Error(t, json.Unmarshal([]byte(`"Hello Errors!"`), &StacktraceError{}))
+	})
+}
+
+func Test_StacktraceErrorFields(t *testing.T) {
+	fields := map[string]any{"request_id": "abc", "user_id": 42}
+	err := NewString("Hello Errors!", WithFields(fields)).ThrowWithFields(map[string]any{"sql": "SELECT 1", "user_id": 43})
+
+	assert.Equal(t, map[string]any{"request_id": "abc", "user_id": 43, "sql": "SELECT 1"}, err.(Fielder).Fields())
+	assert.Equal(t, map[string]any{"request_id": "abc", "user_id": 42}, fields, "caller's map must not be modified")
+
+	err.(Fielder).Fields()["sql"] = "DROP TABLE users"
+	assert.Equal(t, "SELECT 1", err.(Fielder).Fields()["sql"], "returned map must be a copy")
+
+	assert.Equal(t, "Hello Errors!", fmt.Sprintf("%s", err))
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%v", err), "Hello Errors!=>"))
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), "Hello Errors! {request_id=abc, sql=SELECT 1, user_id=43}\n"))
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%#v", err), "Hello Errors! {request_id=abc, sql=SELECT 1, user_id=43}\n#0: "))
+
+	decoded := &StacktraceError{}
+	if assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf("%j", err)), decoded)) {
+		assert.Equal(t, map[string]any{"request_id": "abc", "user_id": float64(43), "sql": "SELECT 1"}, decoded.Fields())
+	}
+}
+
+func Test_StacktraceErrorMaxDepth(t *testing.T) {
+	var recurse func(n int, opts ...StackErrOption) *StacktraceError
+	recurse = func(n int, opts ...StackErrOption) *StacktraceError {
+		if n == 0 {
+			return NewString("Hello Errors!", append(opts, WithStack())...)
 		}
+		return recurse(n-1, opts...)
+	}
 
-		err := stackabc1()
+	t.Run("default depth", func(t *testing.T) {
+		err := recurse(2 * DefaultMaxStackDepth)
+		assert.Len(t, err.StackTrace().Frames, DefaultMaxStackDepth)
+		assert.True(t, err.Truncated())
+	})
 
-		t.Run("printing formats", func(t *testing.T) {
-			type testCase struct {
-				format string
-				output strings.Builder
-				expect string
-			}
+	t.Run("custom depth", func(t *testing.T) {
+		err := recurse(2*DefaultMaxStackDepth, WithMaxDepth(DefaultMaxStackDepth+8))
+		assert.Len(t, err.StackTrace().Frames, DefaultMaxStackDepth+8)
+		assert.True(t, err.StackTrace().Truncated)
+		assert.True(t, strings.HasSuffix(fmt.Sprintf("%v", err), ";..."))
+	})
 
-			cases := []testCase{
-				{
-					format: "s",
-					expect: "Hello Errors!",
-				},
-				{
-					format: "+s",
-					expect: "Hello Errors!",
-				},
-				{
-					format: "v",
-					expect: "Hello Errors!=>github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.1;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.2;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.3;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1;testing.tRunner;runtime.goexit",
-				},
-				{
-					format: " v",
-					expect: "Hello Errors!\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.1\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.2\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.3\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1\ntesting.tRunner\nruntime.goexit",
-				},
-				{
-					format: "-v",
-					expect: fmt.Sprintf("Hello Errors!=>github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.1@%s/stack_test.go:26;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.2@%s/stack_test.go:29;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.3@%s/stack_test.go:32;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1@%s/stack_test.go:35;testing.tRunner@%s/src/testing/testing.go:1690;runtime.goexit@%s/src/runtime/asm_amd64.s:1700", currDir, currDir, currDir, currDir, goRoot, goRoot),
-				},
-				{
-					format: "+v",
-					expect: fmt.Sprintf("Hello Errors!\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.1@%s/stack_test.go:26\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.2@%s/stack_test.go:29\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.3@%s/stack_test.go:32\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1@%s/stack_test.go:35\ntesting.tRunner@%s/src/testing/testing.go:1690\nruntime.goexit@%s/src/runtime/asm_amd64.s:1700", currDir, currDir, currDir, currDir, goRoot, goRoot),
-				},
-				{
-					format: "#v",
-					expect: fmt.Sprintf("Hello Errors!\n#5: github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.1@%s/stack_test.go:26\n#4: github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.2@%s/stack_test.go:29\n#3: github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1.3@%s/stack_test.go:32\n#2: github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func1@%s/stack_test.go:35\n#1: testing.tRunner@%s/src/testing/testing.go:1690\n#0: runtime.goexit@%s/src/runtime/asm_amd64.s:1700", currDir, currDir, currDir, currDir, goRoot, goRoot),
-				},
-				{
-					format: "j",
-					expect: func() string {
-						m := map[string]any{"error": err.Error(), "trace": err.StackTrace()}
-						b, _ := json.Marshal(m)
-						return string(b) + "\n"
-					}(),
-				},
-				{
-					format: "+j",
-					expect: func() string {
-						m := map[string]any{"error": err.Error(), "trace": err.StackTrace()}
-						b, _ := json.MarshalIndent(m, "", "  ")
-						return string(b) + "\n"
-					}(),
-				},
-				{
-					format: "+4j",
-					expect: func() string {
-						m := map[string]any{"error": err.Error(), "trace": err.StackTrace()}
-						b, _ := json.MarshalIndent(m, "", "    ")
-						return string(b) + "\n"
-					}(),
-				},
-			}
+	t.Run("shallow stack", func(t *testing.T) {
+		err := NewString("Hello Errors!", WithStack(), WithMaxDepth(DefaultMaxStackDepth*4))
+		assert.False(t, err.Truncated())
+		assert.NotContains(t, fmt.Sprintf("%v", err), "...")
+	})
 
-			for _, c := range cases {
-				t.Run(c.format, func(t *testing.T) {
-					fmt.Fprintf(&c.output, "%"+c.format, err)
-					if c.output.String() != c.expect {
-						assert.Equal(
-							t,
-							c.expect,
-							c.output.String(),
-							"Doesn't match the expected output",
-						)
-					}
-				})
-			}
-		})
+	t.Run("manual stacktrace", func(t *testing.T) {
+		err := NewString("Hello Errors!", WithMaxDepth(2)).Throw().Throw().(*StacktraceError)
+		assert.False(t, err.Truncated())
+
+		err = err.Throw().(*StacktraceError)
+		assert.True(t, err.Truncated())
+		assert.Len(t, err.StackTrace().Frames, 2)
+		assert.Contains(t, fmt.Sprintf("%j", err), `"truncated":true`)
 	})
 
-	t.Run("manual stacktrace collection", func(t *testing.T) {
-		stackabc3 := func() Error {
-			return New(errors.New("Hello Errors!")).Throw()
-		}
-		stackabc2 := func() Error {
-			return stackabc3().Throw()
-		}
-		stackabc1 := func() Error {
-			return stackabc2().Throw()
+	t.Run("long manual stacktrace", func(t *testing.T) {
+		err := Error(NewString("Hello Errors!"))
+		for i := 0; i < 3*_INLINE_CALL_DEPTH; i++ {
+			err = err.Throw()
 		}
+		assert.Len(t, err.StackTrace().Frames, 3*_INLINE_CALL_DEPTH)
+		assert.False(t, err.(*StacktraceError).Truncated())
+	})
+}
 
-		err := stackabc1().Throw()
+type testCodeError struct {
+	code int
+}
 
-		t.Run("printing formats", func(t *testing.T) {
-			type testCase struct {
-				format string
-				output strings.Builder
-				expect string
-			}
+func (self *testCodeError) Error() string {
+	return "code " + fmt.Sprint(self.code)
+}
 
-			cases := []testCase{
-				{
-					format: "s",
-					expect: "Hello Errors!",
-				},
-				{
-					format: "+s",
-					expect: "Hello Errors!",
-				},
-				{
-					format: "v",
-					expect: "Hello Errors!=>github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.1;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.2;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.3;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2",
-				},
-				{
-					format: " v",
-					expect: "Hello Errors!\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.1\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.2\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.3\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2",
-				},
-				{
-					format: "-v",
-					expect: fmt.Sprintf("Hello Errors!=>github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.1@%s/stack_test.go:117;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.2@%s/stack_test.go:120;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.3@%s/stack_test.go:123;github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2@%s/stack_test.go:126", currDir, currDir, currDir, currDir),
-				},
-				{
-					format: "+v",
-					expect: fmt.Sprintf("Hello Errors!\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.1@%s/stack_test.go:117\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.2@%s/stack_test.go:120\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.3@%s/stack_test.go:123\ngithub.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2@%s/stack_test.go:126", currDir, currDir, currDir, currDir),
-				},
-				{
-					format: "#v",
-					expect: fmt.Sprintf("Hello Errors!\n#3: github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.1@%s/stack_test.go:117\n#2: github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.2@%s/stack_test.go:120\n#1: github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2.3@%s/stack_test.go:123\n#0: github.com/nnishant776/errstack.Test_StackTracedErrorWithDefaults.func2@%s/stack_test.go:126", currDir, currDir, currDir, currDir),
-				},
-				{
-					format: "j",
-					expect: func() string {
-						m := map[string]any{"error": err.Error(), "trace": err.StackTrace()}
-						b, _ := json.Marshal(m)
-						return string(b) + "\n"
-					}(),
-				},
-				{
-					format: "+j",
-					expect: func() string {
-						m := map[string]any{"error": err.Error(), "trace": err.StackTrace()}
-						b, _ := json.MarshalIndent(m, "", "  ")
-						return string(b) + "\n"
-					}(),
-				},
-				{
-					format: "+4j",
-					expect: func() string {
-						m := map[string]any{"error": err.Error(), "trace": err.StackTrace()}
-						b, _ := json.MarshalIndent(m, "", "    ")
-						return string(b) + "\n"
-					}(),
-				},
-			}
+type testListError struct {
+	ids []int
+}
 
-			for _, c := range cases {
-				t.Run(c.format, func(t *testing.T) {
-					fmt.Fprintf(&c.output, "%"+c.format, err)
-					if c.output.String() != c.expect {
-						assert.Equal(
-							t,
-							c.expect,
-							c.output.String(),
-							"Doesn't match the expected output",
-						)
-					}
-				})
-			}
+func (self testListError) Error() string {
+	return fmt.Sprint("ids ", self.ids)
+}
+
+func Test_StacktraceErrorSentinel(t *testing.T) {
+	errNotFound := NewSentinel("not found")
+
+	find := func() Error {
+		return errNotFound.Throw()
+	}
+
+	err1, err2 := find(), find()
+
+	assert.NotSame(t, errNotFound, err1)
+	assert.NotSame(t, err1, err2)
+	assert.Equal(t, 0, errNotFound.frameCount, "sentinel must not be modified by a throw")
+	assert.Equal(t, "not found", err1.Error())
+	assert.Len(t, err1.StackTrace().Frames, 1)
+	assert.Equal(t, "github.com/nnishant776/errstack.Test_StacktraceErrorSentinel.func1", err1.StackTrace().Frames[0].Function)
+
+	assert.True(t, errors.Is(err1, errNotFound))
+	assert.True(t, errors.Is(err1.Throw(), errNotFound))
+	assert.True(t, errors.Is(err1, err2))
+	assert.True(t, errors.Is(fmt.Errorf("wrapped: %w", err1), errNotFound))
+	assert.False(t, errors.Is(err1, NewSentinel("not found")))
+	assert.False(t, errors.Is(NewString("not found"), errNotFound))
+
+	t.Run("sentinel with stack", func(t *testing.T) {
+		errStack := NewSentinel("stack", WithStack())
+		err := errStack.Throw()
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_StacktraceErrorSentinel.func2", err.StackTrace().Frames[0].Function)
+		assert.Greater(t, len(err.StackTrace().Frames), 1)
+		assert.Empty(t, errStack.StackTrace().Frames)
+		assert.True(t, errors.Is(err, errStack))
+	})
+
+	t.Run("string error with sentinel", func(t *testing.T) {
+		codeErr := &testCodeError{code: 404}
+		err := NewString("user 42 not found", WithSentinel(codeErr))
+
+		assert.True(t, errors.Is(err, codeErr))
+		assert.Nil(t, err.Unwrap())
+
+		target := (*testCodeError)(nil)
+		if assert.True(t, errors.As(err, &target)) {
+			assert.Equal(t, 404, target.code)
+		}
+	})
+
+	t.Run("non comparable sentinel", func(t *testing.T) {
+		err := NewString("lookup failed", WithSentinel(testListError{ids: []int{1, 2}}))
+
+		assert.NotPanics(t, func() {
+			assert.False(t, errors.Is(err, testListError{ids: []int{1, 2}}))
+			assert.False(t, errors.Is(err, errNotFound))
 		})
+
+		target := testListError{}
+		if assert.True(t, errors.As(err, &target)) {
+			assert.Equal(t, []int{1, 2}, target.ids)
+		}
 	})
 }
 
+func Test_StacktraceErrorConcurrentThrow(t *testing.T) {
+	errShared := NewSentinel("shared")
+	errPlain := NewString("plain").Throw()
+
+	const goroutines = 64
+
+	results := make(chan Error, 2*goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			results <- errShared.Throw().Throw()
+			results <- errPlain.Throw()
+		}()
+	}
+
+	for i := 0; i < 2*goroutines; i++ {
+		err := <-results
+		assert.Len(t, err.StackTrace().Frames, 2)
+		assert.True(t, errors.Is(err, errShared) || errors.Is(err, errPlain))
+	}
+
+	assert.Empty(t, errShared.StackTrace().Frames)
+	assert.Len(t, errPlain.StackTrace().Frames, 1)
+}
+
 func Benchmark_StacktraceError(b *testing.B) {
 	type ifce interface {
 		StackTrace() pkgerrs.StackTrace
@@ -257,5 +284,15 @@ func Benchmark_StacktraceError(b *testing.B) {
 				fmt.Fprintf(io.Discard, "%-v", err)
 			}
 		})
+
+		b.Run("print error and stacktrace without frame cache", func(b *testing.B) {
+			SetFrameCacheSize(0)
+			defer SetFrameCacheSize(DefaultFrameCacheSize)
+
+			for i := 0; i < b.N; i++ {
+				err := NewString("errstk", WithStack())
+				fmt.Fprintf(io.Discard, "%-v", err)
+			}
+		})
 	})
 }
@@ -9,7 +9,11 @@ const (
 )
 
 type StackTrace struct {
-	Frames []Frame `json:"stack,omitempty"`
+	Frames    []Frame `json:"stack,omitempty"`
+	Truncated bool    `json:"truncated,omitempty"`
+	// SampledOut reports that the sampling policy skipped the capture of the stack, leaving only the frame
+	// of the call site
+	SampledOut bool `json:"sampled_out,omitempty"`
 }
 
 func (self StackTrace) String() string {
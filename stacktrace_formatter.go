@@ -20,11 +20,18 @@ type StackTraceFormatter interface {
 var _ StackTraceFormatter = (*stackTraceFormatter)(nil)
 
 type StackTraceFormatOptions struct {
-	FrameIndent    string
-	FrameSeparator string
-	IndexPrefix    string
-	IndexSuffix    string
-	SkipStackIndex bool
+	FrameIndent      string
+	FrameSeparator   string
+	IndexPrefix      string
+	IndexSuffix      string
+	TruncationMarker string
+	ElisionPrefix    string
+	ElisionSuffix    string
+	Filter           FrameFilter
+	SkipStackIndex   bool
+	// SampledMarker is written after the frames of a stack trace whose capture was skipped by the sampling
+	// policy
+	SampledMarker string
 }
 
 type stackTraceFormatter struct {
@@ -33,13 +40,32 @@ type stackTraceFormatter struct {
 }
 
 func (self *stackTraceFormatter) format(w io.Writer, s StackTrace) {
-	if len(s.Frames) <= 0 {
+	if len(s.Frames) <= 0 && !s.SampledOut {
 		return
 	}
 
-	cnt := len(s.Frames)
+	cnt, written, elided := len(s.Frames), false, 0
 
 	for i, f := range s.Frames {
+		if self.opts.Filter != nil && !self.opts.Filter.Include(f) {
+			elided++
+			continue
+		}
+
+		if elided > 0 {
+			written = self.formatElision(w, elided, written) || written
+			elided = 0
+		}
+
+		if written {
+			switch o := w.(type) {
+			case io.StringWriter:
+				o.WriteString(self.opts.FrameSeparator)
+			default:
+				w.Write(string2Slice(self.opts.FrameSeparator))
+			}
+		}
+
 		if self.opts.SkipStackIndex {
 			switch o := w.(type) {
 			case io.StringWriter:
@@ -63,18 +89,68 @@ func (self *stackTraceFormatter) format(w io.Writer, s StackTrace) {
 		}
 
 		self.ffmt.FormatBuffer(w, f)
+		written = true
+	}
 
-		if i == cnt-1 {
-			continue
+	if elided > 0 {
+		written = self.formatElision(w, elided, written) || written
+	}
+
+	if s.Truncated && self.opts.TruncationMarker != "" {
+		written = self.formatMarker(w, self.opts.TruncationMarker, written)
+	}
+
+	if s.SampledOut && self.opts.SampledMarker != "" {
+		self.formatMarker(w, self.opts.SampledMarker, written)
+	}
+}
+
+func (self *stackTraceFormatter) formatMarker(w io.Writer, marker string, written bool) bool {
+	switch o := w.(type) {
+	case io.StringWriter:
+		if written {
+			o.WriteString(self.opts.FrameSeparator)
+		}
+		o.WriteString(self.opts.FrameIndent)
+		o.WriteString(marker)
+	default:
+		if written {
+			w.Write(string2Slice(self.opts.FrameSeparator))
 		}
+		w.Write(string2Slice(self.opts.FrameIndent))
+		w.Write(string2Slice(marker))
+	}
+
+	return true
+}
 
-		switch o := w.(type) {
-		case io.StringWriter:
+// formatElision writes the marker for a run of frames dropped by the filter. It reports whether anything
+// was written, since an empty prefix and suffix drop the run silently.
+func (self *stackTraceFormatter) formatElision(w io.Writer, elided int, written bool) bool {
+	if self.opts.ElisionPrefix == "" && self.opts.ElisionSuffix == "" {
+		return false
+	}
+
+	switch o := w.(type) {
+	case io.StringWriter:
+		if written {
 			o.WriteString(self.opts.FrameSeparator)
-		default:
+		}
+		o.WriteString(self.opts.FrameIndent)
+		o.WriteString(self.opts.ElisionPrefix)
+		o.WriteString(strconv.FormatInt(int64(elided), 10))
+		o.WriteString(self.opts.ElisionSuffix)
+	default:
+		if written {
 			w.Write(string2Slice(self.opts.FrameSeparator))
 		}
+		w.Write(string2Slice(self.opts.FrameIndent))
+		w.Write(string2Slice(self.opts.ElisionPrefix))
+		w.Write(string2Slice(strconv.FormatInt(int64(elided), 10)))
+		w.Write(string2Slice(self.opts.ElisionSuffix))
 	}
+
+	return true
 }
 
 func (self *stackTraceFormatter) Options() StackTraceFormatOptions {
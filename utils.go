@@ -3,12 +3,13 @@ package errstack
 import (
 	"math"
 	"runtime"
-	"strconv"
+	"strings"
 	"unsafe"
 )
 
 const (
-	_MAX_CALL_DEPTH int = 32
+	_MAX_CALL_DEPTH    int = 32
+	_INLINE_CALL_DEPTH int = 8
 )
 
 func caller(skip int) Frame {
@@ -17,17 +18,38 @@ func caller(skip int) Frame {
 		return Frame{}
 	}
 
+	return caller0(pc)
+}
+
+// caller0 symbolizes the innermost frame of a program counter returned by runtime.Caller
+func caller0(pc uintptr) Frame {
 	frames := genStackTraceFromPCs([]uintptr{pc})
+	if len(frames) <= 0 {
+		return Frame{}
+	}
+
 	return frames[0]
 }
 
 func callerPC(skip int) uintptr {
-	pc, _, _, ok := runtime.Caller(skip + 1)
-	if !ok {
+	c := capturer()
+
+	// RuntimeCapturer is called directly, so that the buffer doesn't escape through the interface and throws
+	// don't allocate it
+	if _, ok := c.(RuntimeCapturer); ok {
+		pcs := [1]uintptr{}
+		if runtime.Callers(skip+2, pcs[:]) == 0 {
+			return math.MaxUint64
+		}
+		return pcs[0]
+	}
+
+	pcs := [1]uintptr{}
+	if c.Capture(skip+1, pcs[:]) == 0 {
 		return math.MaxUint64
 	}
 
-	return pc
+	return pcs[0]
 }
 
 func callers(skip int, cnt int) []Frame {
@@ -48,7 +70,7 @@ func callersPCsBuf(skip int, cnt int, buf []uintptr) []uintptr {
 		return nil
 	}
 
-	count := runtime.Callers(skip+1, buf[:cnt])
+	count := capturer().Capture(skip, buf[:cnt])
 	if count == 0 {
 		return nil
 	}
@@ -62,7 +84,7 @@ func callersPCs(skip int, cnt int) []uintptr {
 	}
 
 	pcs := make([]uintptr, cnt)
-	count := runtime.Callers(skip+1, pcs[:])
+	count := capturer().Capture(skip, pcs[:])
 	if count == 0 {
 		return nil
 	}
@@ -70,27 +92,44 @@ func callersPCs(skip int, cnt int) []uintptr {
 	return pcs[:count]
 }
 
-func genStackTraceFromPCs(pcs []uintptr) []Frame {
-	if len(pcs) <= 0 {
-		return nil
+// panicPCs trims the recovery frames from a stack captured while a panic is in flight, so that the
+// returned slice starts at the function which panicked
+func panicPCs(pcs []uintptr) []uintptr {
+	start := -1
+
+	for i, pc := range pcs {
+		if caller0(pc).Function == "runtime.gopanic" {
+			start = i + 1
+		}
 	}
 
-	frames := make([]Frame, 0, len(pcs))
-	callFrames := runtime.CallersFrames(pcs)
+	if start < 0 {
+		return pcs
+	}
 
-	for {
-		f, ok := callFrames.Next()
-		frames = append(frames, Frame{
-			File:     f.File,
-			Function: f.Function,
-			Line:     strconv.FormatInt(int64(f.Line), 10),
-		})
-		if !ok {
+	for ; start < len(pcs); start++ {
+		if !strings.HasPrefix(caller0(pcs[start]).Function, "runtime.") {
 			break
 		}
 	}
 
-	return frames
+	return pcs[start:]
+}
+
+func mergeFields(dst, src map[string]any) map[string]any {
+	if len(src) <= 0 {
+		return dst
+	}
+
+	fields := make(map[string]any, len(dst)+len(src))
+	for k, v := range dst {
+		fields[k] = v
+	}
+	for k, v := range src {
+		fields[k] = v
+	}
+
+	return fields
 }
 
 func string2Slice(s string) []byte {
@@ -0,0 +1,99 @@
+package errstack
+
+import (
+	"fmt"
+)
+
+// Wrap annotates err with msg, describing what was being done when err was returned, e.g. "loading config".
+// The annotation is bound to the frame of the caller, while err keeps its identity and stack trace, as if it
+// was thrown with ThrowMsg. The error string reads "msg: err". Wrap returns nil if err is nil.
+//
+//go:noinline
+func Wrap(err error, msg string) error {
+	return wrap(1, err, msg)
+}
+
+// Wrapf is the same as Wrap, except the annotation is formatted according to format
+//
+//go:noinline
+func Wrapf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return wrap(1, err, fmt.Sprintf(format, args...))
+}
+
+//go:noinline
+func wrap(skip int, err error, msg string) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *ChainedStacktraceError:
+		return e.throwMsg(skip+1, msg)
+	case *StacktraceError:
+		return e.throwMsg(skip+1, msg)
+	case ChainedError:
+		if e.Inner() == nil {
+			return newStacktraceError(err).throwMsg(skip+1, msg)
+		}
+		return &ChainedStacktraceError{
+			nextErr: e.Next(),
+			currErr: adoptError(e.Inner()).throwMsg(skip+1, msg),
+		}
+	case Error:
+		return adoptError(e).throwMsg(skip+1, msg)
+	default:
+		return newStacktraceError(err).throwMsg(skip+1, msg)
+	}
+}
+
+// Annotate wraps the error pointed to by errp like Wrapf, binding the annotation to the frame of the caller.
+// It is meant to be deferred with a named return value, e.g.
+//
+//	defer errstack.Annotate(&err, "processing order %d", id)
+//
+// Annotate does nothing if errp or the error it points to is nil.
+//
+//go:noinline
+func Annotate(errp *error, format string, args ...any) {
+	if errp == nil || *errp == nil {
+		return
+	}
+
+	*errp = wrap(1, *errp, fmt.Sprintf(format, args...))
+}
+
+// Rethrow throws the error pointed to by errp from the frame of the caller. Errors which aren't errstack
+// errors are converted with New first. It is meant to be deferred with a named return value, e.g.
+//
+//	defer errstack.Rethrow(&err)
+//
+// Rethrow does nothing if errp or the error it points to is nil.
+//
+//go:noinline
+func Rethrow(errp *error) {
+	if errp == nil || *errp == nil {
+		return
+	}
+
+	switch e := (*errp).(type) {
+	case *ChainedStacktraceError:
+		*errp = e.throwSkip(1)
+	case *StacktraceError:
+		*errp = e.ThrowSkip(1)
+	case ChainedError:
+		if e.Inner() == nil {
+			*errp = newStacktraceError(e).ThrowSkip(1)
+			return
+		}
+		*errp = &ChainedStacktraceError{
+			nextErr: e.Next(),
+			currErr: e.Inner().ThrowSkip(1),
+		}
+	case Error:
+		*errp = e.ThrowSkip(1)
+	default:
+		*errp = newStacktraceError(e).ThrowSkip(1)
+	}
+}
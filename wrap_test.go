@@ -0,0 +1,244 @@
+package errstack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//go:noinline
+func wrapLoadConfig(err error, name string) error {
+	return Wrapf(err, "loading config %s", name)
+}
+
+//go:noinline
+func wrapHandleRequest(err error) error {
+	return Wrap(wrapLoadConfig(err, "app.yaml"), "handling request")
+}
+
+// foreignError and foreignChain implement the interfaces of the package outside of it
+type foreignError struct{ *StacktraceError }
+
+type foreignChain struct{ *ChainedStacktraceError }
+
+//go:noinline
+func wrapInPlace() error {
+	return Wrap(NewString("invalid", WithStack()), "validating")
+}
+
+func Test_Wrap(t *testing.T) {
+	t.Run("error string", func(t *testing.T) {
+		errNotFound := NewSentinel("file not found")
+
+		err := wrapHandleRequest(errNotFound)
+		assert.Equal(t, "handling request: loading config app.yaml: file not found", err.Error())
+		assert.Equal(t, "file not found", errNotFound.Error())
+		assert.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("messages bound to frames", func(t *testing.T) {
+		err := wrapHandleRequest(NewString("file not found"))
+
+		stErr, ok := err.(Error)
+		if !assert.True(t, ok) {
+			t.FailNow()
+		}
+
+		frames := stErr.StackTrace().Frames
+		if assert.Len(t, frames, 2) {
+			assert.Equal(t, "github.com/nnishant776/errstack.wrapLoadConfig", frames[0].Function)
+			assert.Equal(t, "loading config app.yaml", frames[0].Message)
+			assert.Equal(t, "github.com/nnishant776/errstack.wrapHandleRequest", frames[1].Function)
+			assert.Equal(t, "handling request", frames[1].Message)
+		}
+
+		ffmt := DefaultStackFrameFormatter.Copy()
+		assert.Equal(t, "github.com/nnishant776/errstack.wrapLoadConfig", ffmt.SetOptions(FrameFormatterOptions{SkipLocation: true, SkipMessage: true}).Format(frames[0]))
+
+		assert.Equal(t, "handling request: loading config app.yaml: file not found=>github.com/nnishant776/errstack.wrapLoadConfig [loading config app.yaml];github.com/nnishant776/errstack.wrapHandleRequest [handling request]", fmt.Sprintf("%v", err))
+	})
+
+	t.Run("captured stack", func(t *testing.T) {
+		inner := NewString("file not found", WithStack())
+
+		err := wrapHandleRequest(inner)
+		frames := err.(Error).StackTrace().Frames
+
+		assert.Equal(t, len(inner.StackTrace().Frames)+2, len(frames))
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Wrap.func3", frames[0].Function)
+		assert.Equal(t, "", frames[0].Message)
+		assert.Equal(t, "loading config app.yaml", frames[len(frames)-2].Message)
+		assert.Equal(t, "handling request", frames[len(frames)-1].Message)
+		assert.Empty(t, inner.StackTrace().Frames[0].Message)
+
+		rethrown := func() error {
+			return Wrap(inner, "rethrown")
+		}()
+		rethrownFrames := rethrown.(Error).StackTrace().Frames
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Wrap.func3.1", rethrownFrames[len(rethrownFrames)-1].Function)
+		assert.Equal(t, "rethrown", rethrownFrames[len(rethrownFrames)-1].Message)
+
+		inPlace := wrapInPlace().(Error).StackTrace().Frames
+		assert.Equal(t, "github.com/nnishant776/errstack.wrapInPlace", inPlace[0].Function)
+		assert.Equal(t, "validating", inPlace[0].Message)
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Wrap.func3", inPlace[1].Function)
+	})
+
+	t.Run("chained error", func(t *testing.T) {
+		chErr := NewChainString("outer").Chain(NewString("inner"))
+
+		err := Wrap(chErr, "saving")
+		assert.Equal(t, "saving: outer, inner", err.Error())
+		assert.Equal(t, "outer, inner", chErr.Error())
+
+		thrown := chErr.(*ChainedStacktraceError).ThrowMsg("retrying")
+		assert.Equal(t, "retrying: outer, inner", thrown.Error())
+		assert.Equal(t, "github.com/nnishant776/errstack.Test_Wrap.func4", thrown.Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("foreign error", func(t *testing.T) {
+		err := Wrap(io.EOF, "reading header")
+		assert.Equal(t, "reading header: EOF", err.Error())
+		assert.True(t, errors.Is(err, io.EOF))
+		assert.Equal(t, "reading header", err.(Error).StackTrace().Frames[0].Message)
+	})
+
+	t.Run("foreign implementations", func(t *testing.T) {
+		inner := NewString("file not found", WithStack())
+
+		err := wrapLoadConfig(foreignError{inner}, "app.yaml")
+		assert.Equal(t, "loading config app.yaml: file not found", err.Error())
+		assert.ErrorIs(t, err, inner)
+
+		frames := err.(Error).StackTrace().Frames
+		if assert.Len(t, frames, len(inner.StackTrace().Frames)+1) {
+			assert.Equal(t, inner.StackTrace().Frames, frames[:len(frames)-1])
+			assert.Equal(t, "github.com/nnishant776/errstack.wrapLoadConfig", frames[len(frames)-1].Function)
+			assert.Equal(t, "loading config app.yaml", frames[len(frames)-1].Message)
+		}
+
+		chErr := wrapLoadConfig(foreignChain{NewChainString("outer").Chain(NewString("inner")).(*ChainedStacktraceError)}, "db.yaml")
+		assert.Equal(t, "loading config db.yaml: outer, inner", chErr.Error())
+		assert.Equal(t, "github.com/nnishant776/errstack.wrapLoadConfig", chErr.(ChainedError).Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		assert.Nil(t, Wrap(nil, "unused"))
+		assert.Nil(t, Wrapf(nil, "unused %d", 1))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		err := wrapHandleRequest(NewString("file not found"))
+
+		data, jerr := json.Marshal(err)
+		if !assert.NoError(t, jerr) {
+			t.FailNow()
+		}
+
+		decoded := &StacktraceError{}
+		if assert.NoError(t, json.Unmarshal(data, decoded)) {
+			assert.Equal(t, err.Error(), decoded.Error())
+			assert.Equal(t, withoutAddresses(err.(Error).StackTrace()), decoded.StackTrace())
+			assert.Equal(t, "handling request", decoded.StackTrace().Frames[1].Message)
+		}
+	})
+}
+
+//go:noinline
+func annotatedProcess(id int, cause error) (err error) {
+	defer Annotate(&err, "processing order %d", id)
+
+	return cause
+}
+
+//go:noinline
+func rethrownProcess(cause error) (err error) {
+	defer Rethrow(&err)
+
+	return cause
+}
+
+//go:noinline
+func rethrownTwice(cause error) (err error) {
+	defer Rethrow(&err)
+
+	return rethrownProcess(cause)
+}
+
+func Test_DeferredHelpers(t *testing.T) {
+	t.Run("annotate", func(t *testing.T) {
+		errNotFound := NewSentinel("not found")
+
+		err := annotatedProcess(42, errNotFound)
+		assert.Equal(t, "processing order 42: not found", err.Error())
+		assert.ErrorIs(t, err, errNotFound)
+
+		frames := err.(Error).StackTrace().Frames
+		if assert.Len(t, frames, 1) {
+			assert.Equal(t, "github.com/nnishant776/errstack.annotatedProcess", frames[0].Function)
+			assert.Equal(t, "processing order 42", frames[0].Message)
+		}
+
+		chErr := annotatedProcess(7, NewChainString("outer").Chain(NewString("inner")))
+		assert.Equal(t, "processing order 7: outer, inner", chErr.Error())
+		assert.Equal(t, "github.com/nnishant776/errstack.annotatedProcess", chErr.(ChainedError).Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("rethrow", func(t *testing.T) {
+		err := rethrownTwice(NewString("not found"))
+		assert.Equal(t, "not found", err.Error())
+		assert.Equal(t, []string{
+			"github.com/nnishant776/errstack.rethrownProcess",
+			"github.com/nnishant776/errstack.rethrownTwice",
+		}, functionNames(err.(Error).StackTrace()))
+
+		chErr := rethrownProcess(NewChainString("outer").Chain(NewString("inner")))
+		assert.Equal(t, "outer, inner", chErr.Error())
+		assert.Equal(t, "github.com/nnishant776/errstack.rethrownProcess", chErr.(ChainedError).Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("rethrow sentinel with stack", func(t *testing.T) {
+		errNotFound := NewSentinel("not found", WithStack())
+
+		err := rethrownProcess(errNotFound)
+		assert.ErrorIs(t, err, errNotFound)
+
+		frames := err.(Error).StackTrace().Frames
+		if assert.NotEmpty(t, frames) {
+			assert.Equal(t, "github.com/nnishant776/errstack.rethrownProcess", frames[0].Function)
+			assert.Equal(t, "github.com/nnishant776/errstack.Test_DeferredHelpers.func3", frames[1].Function)
+		}
+	})
+
+	t.Run("foreign error", func(t *testing.T) {
+		err := rethrownProcess(io.EOF)
+		assert.True(t, errors.Is(err, io.EOF))
+		assert.Equal(t, "github.com/nnishant776/errstack.rethrownProcess", err.(Error).StackTrace().Frames[0].Function)
+	})
+
+	t.Run("foreign implementations", func(t *testing.T) {
+		err := rethrownTwice(foreignError{NewString("not found")})
+		assert.Equal(t, []string{
+			"github.com/nnishant776/errstack.rethrownProcess",
+			"github.com/nnishant776/errstack.rethrownTwice",
+		}, functionNames(err.(Error).StackTrace()))
+
+		chErr := rethrownProcess(foreignChain{NewChainString("outer").Chain(NewString("inner")).(*ChainedStacktraceError)})
+		assert.Equal(t, "outer, inner", chErr.Error())
+		assert.Equal(t, "github.com/nnishant776/errstack.rethrownProcess", chErr.(ChainedError).Inner().StackTrace().Frames[0].Function)
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		assert.NoError(t, annotatedProcess(1, nil))
+		assert.NoError(t, rethrownProcess(nil))
+
+		assert.NotPanics(t, func() {
+			Annotate(nil, "unused")
+			Rethrow(nil)
+		})
+	})
+}